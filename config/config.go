@@ -0,0 +1,580 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"yt_search_server/cors"
+	"yt_search_server/logging"
+	"yt_search_server/ratelimit"
+	"yt_search_server/youtube"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+type Duration time.Duration
+
+func (duration *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*duration = Duration(value)
+	return nil
+}
+
+func (duration Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(duration).String()), nil
+}
+
+type Config struct {
+	Host     string   `json:"host" toml:"host" yaml:"host"`
+	Port     int      `json:"port" toml:"port" yaml:"port"`
+	ApiKeys  []string `json:"apiKeys" toml:"apiKeys" yaml:"apiKeys"`
+	StoreDir string   `json:"storeDir" toml:"storeDir" yaml:"storeDir"`
+
+	TrackedChannels []string `json:"trackedChannels" toml:"trackedChannels" yaml:"trackedChannels"`
+	SampleInterval  Duration `json:"sampleInterval" toml:"sampleInterval" yaml:"sampleInterval"`
+	// HistoryRetention is how long every sample is kept; older ones are
+	// thinned to one a day. Zero keeps every sample.
+	HistoryRetention Duration `json:"historyRetention" toml:"historyRetention" yaml:"historyRetention"`
+
+	ReadTimeout       Duration `json:"readTimeout" toml:"readTimeout" yaml:"readTimeout"`
+	ReadHeaderTimeout Duration `json:"readHeaderTimeout" toml:"readHeaderTimeout" yaml:"readHeaderTimeout"`
+	WriteTimeout      Duration `json:"writeTimeout" toml:"writeTimeout" yaml:"writeTimeout"`
+	IdleTimeout       Duration `json:"idleTimeout" toml:"idleTimeout" yaml:"idleTimeout"`
+	MaxHeaderBytes    int      `json:"maxHeaderBytes" toml:"maxHeaderBytes" yaml:"maxHeaderBytes"`
+	ShutdownTimeout   Duration `json:"shutdownTimeout" toml:"shutdownTimeout" yaml:"shutdownTimeout"`
+
+	WindowRadius     int      `json:"windowRadius" toml:"windowRadius" yaml:"windowRadius"`
+	PlaylistIndexTTL Duration `json:"playlistIndexTtl" toml:"playlistIndexTtl" yaml:"playlistIndexTtl"`
+	VideoDetailsTTL  Duration `json:"videoDetailsTtl" toml:"videoDetailsTtl" yaml:"videoDetailsTtl"`
+	ChannelIndexTTL  Duration `json:"channelIndexTtl" toml:"channelIndexTtl" yaml:"channelIndexTtl"`
+
+	CorsOrigins     []string `json:"corsOrigins" toml:"corsOrigins" yaml:"corsOrigins"`
+	CorsMethods     []string `json:"corsMethods" toml:"corsMethods" yaml:"corsMethods"`
+	CorsHeaders     []string `json:"corsHeaders" toml:"corsHeaders" yaml:"corsHeaders"`
+	CorsCredentials bool     `json:"corsCredentials" toml:"corsCredentials" yaml:"corsCredentials"`
+	CorsMaxAge      Duration `json:"corsMaxAge" toml:"corsMaxAge" yaml:"corsMaxAge"`
+
+	Auth       string `json:"auth" toml:"auth" yaml:"auth"`
+	AdminToken string `json:"adminToken" toml:"adminToken" yaml:"adminToken"`
+
+	RateLimit          float64        `json:"rateLimit" toml:"rateLimit" yaml:"rateLimit"`
+	RateLimitBurst     int            `json:"rateLimitBurst" toml:"rateLimitBurst" yaml:"rateLimitBurst"`
+	RateLimitCosts     map[string]int `json:"rateLimitCosts" toml:"rateLimitCosts" yaml:"rateLimitCosts"`
+	RateLimitAllowlist []string       `json:"rateLimitAllowlist" toml:"rateLimitAllowlist" yaml:"rateLimitAllowlist"`
+	TrustedProxies     []string       `json:"trustedProxies" toml:"trustedProxies" yaml:"trustedProxies"`
+
+	LogFormat string `json:"logFormat" toml:"logFormat" yaml:"logFormat"`
+	LogLevel  string `json:"logLevel" toml:"logLevel" yaml:"logLevel"`
+
+	// File is the config file that was read, if any.
+	File string `json:"-" toml:"-" yaml:"-"`
+	// PrintConfig asks for the effective configuration to be printed.
+	PrintConfig bool `json:"-" toml:"-" yaml:"-"`
+}
+
+func Defaults() *Config {
+	return &Config{
+		Port:              8080,
+		ApiKeys:           []string{},
+		StoreDir:          "data",
+		TrackedChannels:   []string{},
+		SampleInterval:    Duration(6 * time.Hour),
+		HistoryRetention:  Duration(30 * 24 * time.Hour),
+		ReadTimeout:       Duration(15 * time.Second),
+		ReadHeaderTimeout: Duration(5 * time.Second),
+		WriteTimeout:      Duration(5 * time.Minute),
+		IdleTimeout:       Duration(2 * time.Minute),
+		MaxHeaderBytes:    1 << 20,
+		ShutdownTimeout:   Duration(30 * time.Second),
+		WindowRadius:      youtube.DefaultWindowRadius,
+		PlaylistIndexTTL:  Duration(youtube.PlaylistIndexTTL),
+		VideoDetailsTTL:   Duration(youtube.VideoDetailsTTL),
+		ChannelIndexTTL:   Duration(youtube.ChannelIndexTTL),
+		CorsOrigins:       []string{"*"},
+		CorsMethods:       []string{"GET", "POST", "PUT", "DELETE"},
+		CorsHeaders:       []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID"},
+		CorsMaxAge:        Duration(10 * time.Minute),
+		Auth:              "anonymous",
+		RateLimit:         60,
+		RateLimitBurst:    60,
+		// Routes that page through whole channels cost more; probes and
+		// metrics are free.
+		RateLimitCosts: map[string]int{
+			"/":                        0,
+			"/healthz":                 0,
+			"/readyz":                  0,
+			"/metrics":                 0,
+			"/videos/":                 10,
+			"/v2/channels/{id}/export": 10,
+			"/v2/timeline/videos/{videoId}/neighbors": 10,
+		},
+		RateLimitAllowlist: []string{},
+		TrustedProxies:     []string{},
+		LogFormat:          "text",
+		LogLevel:           "info",
+	}
+}
+
+type setting struct {
+	flag  string
+	env   []string
+	usage string
+	set   func(config *Config, value string) error
+}
+
+func setString(field func(config *Config) *string) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		*field(config) = value
+		return nil
+	}
+}
+
+func setInt(field func(config *Config) *int) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(config) = number
+		return nil
+	}
+}
+
+func setFloat(field func(config *Config) *float64) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(config) = number
+		return nil
+	}
+}
+
+func setBool(field func(config *Config) *bool) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*field(config) = boolean
+		return nil
+	}
+}
+
+func setDuration(field func(config *Config) *Duration) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		err := field(config).UnmarshalText([]byte(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 6h", value)
+		}
+		return nil
+	}
+}
+
+func setList(field func(config *Config) *[]string) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field(config) = list
+		return nil
+	}
+}
+
+// setCosts takes route=cost pairs, which override the costs of those routes
+// only.
+func setCosts(field func(config *Config) *map[string]int) func(*Config, string) error {
+	return func(config *Config, value string) error {
+		costs := make(map[string]int)
+		for route, cost := range *field(config) {
+			costs[route] = cost
+		}
+		for _, pair := range strings.Split(value, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			route, cost, ok := strings.Cut(pair, "=")
+			number, err := strconv.Atoi(strings.TrimSpace(cost))
+			if !ok || err != nil {
+				return fmt.Errorf("%q is not a route=cost pair such as /videos/=10", pair)
+			}
+			costs[strings.TrimSpace(route)] = number
+		}
+		*field(config) = costs
+		return nil
+	}
+}
+
+// Settings are applied from the environment in this order, so the plural
+// YOUTUBE_DATA_SERVICE_API_KEYS overrides the older single-key variable.
+var settings = []setting{
+	{"host", []string{"SERVER_HOST"}, "interface to listen on",
+		setString(func(c *Config) *string { return &c.Host })},
+	{"port", []string{"SERVER_PORT"}, "port to listen on",
+		setInt(func(c *Config) *int { return &c.Port })},
+	{"api-keys", []string{"YOUTUBE_DATA_SERVICE_API_KEY", "YOUTUBE_DATA_SERVICE_API_KEYS"},
+		"comma-separated YouTube Data API keys, used in turn",
+		setList(func(c *Config) *[]string { return &c.ApiKeys })},
+	{"store-dir", []string{"STORE_DIR"}, "directory of the statistics store",
+		setString(func(c *Config) *string { return &c.StoreDir })},
+	{"tracked-channels", []string{"TRACKED_CHANNELS"}, "comma-separated channel IDs to track",
+		setList(func(c *Config) *[]string { return &c.TrackedChannels })},
+	{"sample-interval", []string{"SAMPLE_INTERVAL"}, "time between statistics samples",
+		setDuration(func(c *Config) *Duration { return &c.SampleInterval })},
+	{"history-retention", []string{"HISTORY_RETENTION"}, "age after which samples are thinned to one a day",
+		setDuration(func(c *Config) *Duration { return &c.HistoryRetention })},
+	{"read-timeout", []string{"READ_TIMEOUT"}, "time allowed to read a request",
+		setDuration(func(c *Config) *Duration { return &c.ReadTimeout })},
+	{"read-header-timeout", []string{"READ_HEADER_TIMEOUT"}, "time allowed to read request headers",
+		setDuration(func(c *Config) *Duration { return &c.ReadHeaderTimeout })},
+	{"write-timeout", []string{"WRITE_TIMEOUT"}, "time allowed to write a response",
+		setDuration(func(c *Config) *Duration { return &c.WriteTimeout })},
+	{"idle-timeout", []string{"IDLE_TIMEOUT"}, "time an idle keep-alive connection is kept",
+		setDuration(func(c *Config) *Duration { return &c.IdleTimeout })},
+	{"max-header-bytes", []string{"MAX_HEADER_BYTES"}, "largest accepted request header, in bytes",
+		setInt(func(c *Config) *int { return &c.MaxHeaderBytes })},
+	{"shutdown-timeout", []string{"SHUTDOWN_TIMEOUT"}, "time allowed to drain on shutdown",
+		setDuration(func(c *Config) *Duration { return &c.ShutdownTimeout })},
+	{"window-radius", []string{"WINDOW_RADIUS"}, "videos on each side of the anchor in a window",
+		setInt(func(c *Config) *int { return &c.WindowRadius })},
+	{"playlist-index-ttl", []string{"PLAYLIST_INDEX_TTL"}, "lifetime of cached playlist indexes",
+		setDuration(func(c *Config) *Duration { return &c.PlaylistIndexTTL })},
+	{"video-details-ttl", []string{"VIDEO_DETAILS_TTL"}, "lifetime of cached video details",
+		setDuration(func(c *Config) *Duration { return &c.VideoDetailsTTL })},
+	{"channel-index-ttl", []string{"CHANNEL_INDEX_TTL"}, "lifetime of cached channel indexes",
+		setDuration(func(c *Config) *Duration { return &c.ChannelIndexTTL })},
+	{"cors-origins", []string{"CORS_ORIGINS"}, "comma-separated origins allowed to call the API",
+		setList(func(c *Config) *[]string { return &c.CorsOrigins })},
+	{"cors-methods", []string{"CORS_METHODS"}, "comma-separated methods allowed in cross-origin requests",
+		setList(func(c *Config) *[]string { return &c.CorsMethods })},
+	{"cors-headers", []string{"CORS_HEADERS"}, "comma-separated request headers allowed in cross-origin requests",
+		setList(func(c *Config) *[]string { return &c.CorsHeaders })},
+	{"cors-credentials", []string{"CORS_CREDENTIALS"}, "allow cross-origin requests with credentials",
+		setBool(func(c *Config) *bool { return &c.CorsCredentials })},
+	{"cors-max-age", []string{"CORS_MAX_AGE"}, "time browsers may cache a preflight response",
+		setDuration(func(c *Config) *Duration { return &c.CorsMaxAge })},
+	{"auth", []string{"AUTH"}, "anonymous, or keys to require a client API key",
+		setString(func(c *Config) *string { return &c.Auth })},
+	{"admin-token", []string{"ADMIN_TOKEN"}, "token with the admin scope, for creating the first keys",
+		setString(func(c *Config) *string { return &c.AdminToken })},
+	{"rate-limit", []string{"RATE_LIMIT"}, "tokens each client regains per minute; 0 disables limiting",
+		setFloat(func(c *Config) *float64 { return &c.RateLimit })},
+	{"rate-limit-burst", []string{"RATE_LIMIT_BURST"}, "most tokens a client can hold",
+		setInt(func(c *Config) *int { return &c.RateLimitBurst })},
+	{"rate-limit-costs", []string{"RATE_LIMIT_COSTS"}, "comma-separated route=cost pairs, such as /videos/=10",
+		setCosts(func(c *Config) *map[string]int { return &c.RateLimitCosts })},
+	{"rate-limit-allowlist", []string{"RATE_LIMIT_ALLOWLIST"}, "comma-separated IPs, CIDR ranges and client keys that are not limited",
+		setList(func(c *Config) *[]string { return &c.RateLimitAllowlist })},
+	{"trusted-proxies", []string{"TRUSTED_PROXIES"}, "comma-separated CIDR ranges whose X-Forwarded-For is believed",
+		setList(func(c *Config) *[]string { return &c.TrustedProxies })},
+	{"log-format", []string{"LOG_FORMAT"}, "log output format, text or json",
+		setString(func(c *Config) *string { return &c.LogFormat })},
+	{"log-level", []string{"LOG_LEVEL"}, "lowest level logged: debug, info, warn or error",
+		setString(func(c *Config) *string { return &c.LogLevel })},
+}
+
+type flagValue struct {
+	setting *setting
+	value   string
+}
+
+// Load builds the configuration from, in increasing precedence, the defaults,
+// a config file, the environment (including a .env file) and args.
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("yt_search_server", flag.ContinueOnError)
+
+	file := flags.String("config", "", "JSON, TOML or YAML config file (or CONFIG_FILE)")
+	printConfig := flags.Bool("print-config", false, "print the effective configuration and exit")
+
+	// Flags are recorded and replayed last, after the file and environment.
+	flagValues := []flagValue{}
+	for i := range settings {
+		setting := &settings[i]
+		flags.Func(setting.flag, setting.usage, func(value string) error {
+			flagValues = append(flagValues, flagValue{setting, value})
+			return nil
+		})
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	// Variables already set in the environment take precedence over .env.
+	err = godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("error loading .env file: %s", err)
+	}
+
+	config := Defaults()
+	config.PrintConfig = *printConfig
+
+	config.File = *file
+	if config.File == "" {
+		config.File = os.Getenv("CONFIG_FILE")
+	}
+	if config.File != "" {
+		err = readFile(config.File, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range settings {
+		for _, name := range settings[i].env {
+			value, ok := os.LookupEnv(name)
+			if !ok || value == "" {
+				continue
+			}
+			err = settings[i].set(config, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %s", name, err)
+			}
+		}
+	}
+
+	for _, flagValue := range flagValues {
+		err = flagValue.setting.set(config, flagValue.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -%s: %s", flagValue.setting.flag, err)
+		}
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// readFile decodes the file over config, rejecting keys it does not know.
+func readFile(path string, config *Config) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %s", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	case ".toml":
+		var metadata toml.MetaData
+		metadata, err = toml.Decode(string(contents), config)
+		if err == nil && len(metadata.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown key %s", metadata.Undecoded()[0])
+		}
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	default:
+		return fmt.Errorf(
+			"config file %s must end in .json, .toml, .yaml or .yml", path,
+		)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error decoding config file %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (config *Config) Validate() error {
+	problems := []string{}
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, "  - "+fmt.Sprintf(format, args...))
+	}
+
+	if config.Port < 1 || config.Port > 65535 {
+		problem("port must be between 1 and 65535, got %d", config.Port)
+	}
+
+	if len(config.ApiKeys) == 0 {
+		problem("at least one YouTube Data API key is required (YOUTUBE_DATA_SERVICE_API_KEYS, -api-keys or apiKeys)")
+	}
+	seenKeys := make(map[string]bool)
+	for i, key := range config.ApiKeys {
+		if strings.TrimSpace(key) == "" {
+			problem("API key %d is empty", i+1)
+		} else if seenKeys[key] {
+			problem("API key %d repeats an earlier key", i+1)
+		}
+		seenKeys[key] = true
+	}
+
+	if config.StoreDir == "" {
+		problem("store directory must not be empty")
+	}
+
+	for _, channelId := range config.TrackedChannels {
+		if strings.ContainsAny(channelId, " /?#") {
+			problem("tracked channel %q is not a channel ID", channelId)
+		}
+	}
+
+	durations := []struct {
+		name    string
+		value   Duration
+		minimum time.Duration
+	}{
+		{"sample interval", config.SampleInterval, time.Minute},
+		{"read timeout", config.ReadTimeout, time.Second},
+		{"read header timeout", config.ReadHeaderTimeout, time.Second},
+		{"write timeout", config.WriteTimeout, time.Second},
+		{"idle timeout", config.IdleTimeout, time.Second},
+		{"shutdown timeout", config.ShutdownTimeout, time.Second},
+		{"playlist index TTL", config.PlaylistIndexTTL, time.Second},
+		{"video details TTL", config.VideoDetailsTTL, time.Second},
+		{"channel index TTL", config.ChannelIndexTTL, time.Second},
+	}
+	for _, duration := range durations {
+		if time.Duration(duration.value) < duration.minimum {
+			problem(
+				"%s must be at least %s, got %s",
+				duration.name, duration.minimum, time.Duration(duration.value),
+			)
+		}
+	}
+
+	// Anything shorter would thin the day still being sampled.
+	if retention := time.Duration(config.HistoryRetention); retention != 0 && retention < 24*time.Hour {
+		problem("history retention must be 0 or at least 24h, got %s", retention)
+	}
+
+	if config.ReadHeaderTimeout > config.ReadTimeout {
+		problem("read header timeout must not exceed the read timeout")
+	}
+
+	if config.MaxHeaderBytes < 4096 {
+		problem("max header bytes must be at least 4096, got %d", config.MaxHeaderBytes)
+	}
+
+	if config.WindowRadius < 1 || config.WindowRadius > youtube.MaxWindowRadius {
+		problem(
+			"window radius must be between 1 and %d, got %d",
+			youtube.MaxWindowRadius, config.WindowRadius,
+		)
+	}
+
+	if _, err := cors.New(config.CorsOptions()); err != nil {
+		problem("CORS %s", err)
+	}
+
+	if config.Auth != "anonymous" && config.Auth != "keys" {
+		problem("auth must be anonymous or keys, got %q", config.Auth)
+	}
+	if config.AdminToken != "" && len(config.AdminToken) < 32 {
+		problem("admin token must be at least 32 characters long")
+	}
+
+	if _, err := ratelimit.NewLimiter(config.RateLimitOptions()); err != nil {
+		problem("rate limit %s", err)
+	}
+
+	if config.LogFormat != "text" && config.LogFormat != "json" {
+		problem("log format must be text or json, got %q", config.LogFormat)
+	}
+	if _, err := logging.ParseLevel(config.LogLevel); err != nil {
+		problem("log level %s", err)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid configuration:\n%s", strings.Join(problems, "\n"))
+}
+
+func (config *Config) Addr() string {
+	return fmt.Sprintf("%s:%d", config.Host, config.Port)
+}
+
+// Redacted returns a copy that is safe to print or log.
+func (config *Config) Redacted() *Config {
+	redactedConfig := *config
+	redactedConfig.ApiKeys = make([]string, len(config.ApiKeys))
+	for i := range redactedConfig.ApiKeys {
+		redactedConfig.ApiKeys[i] = redacted
+	}
+	if config.AdminToken != "" {
+		redactedConfig.AdminToken = redacted
+	}
+
+	// Allowlisted client keys are secrets too.
+	redactedConfig.RateLimitAllowlist = make([]string, len(config.RateLimitAllowlist))
+	for i, entry := range config.RateLimitAllowlist {
+		if ratelimit.IsAddress(entry) {
+			redactedConfig.RateLimitAllowlist[i] = entry
+		} else {
+			redactedConfig.RateLimitAllowlist[i] = redacted
+		}
+	}
+	return &redactedConfig
+}
+
+func (config *Config) YouTubeOptions() youtube.Options {
+	return youtube.Options{
+		ApiKeys:          config.ApiKeys,
+		WindowRadius:     config.WindowRadius,
+		PlaylistIndexTTL: time.Duration(config.PlaylistIndexTTL),
+		VideoDetailsTTL:  time.Duration(config.VideoDetailsTTL),
+		ChannelIndexTTL:  time.Duration(config.ChannelIndexTTL),
+	}
+}
+
+func (config *Config) RateLimitOptions() ratelimit.Options {
+	return ratelimit.Options{
+		PerMinute:      config.RateLimit,
+		Burst:          config.RateLimitBurst,
+		Costs:          config.RateLimitCosts,
+		DefaultCost:    1,
+		Allowlist:      config.RateLimitAllowlist,
+		TrustedProxies: config.TrustedProxies,
+	}
+}
+
+func (config *Config) CorsOptions() cors.Options {
+	return cors.Options{
+		Origins:     config.CorsOrigins,
+		Methods:     config.CorsMethods,
+		Headers:     config.CorsHeaders,
+		Credentials: config.CorsCredentials,
+		MaxAge:      time.Duration(config.CorsMaxAge),
+	}
+}
+
+func (config *Config) AuthRequired() bool {
+	return config.Auth == "keys"
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"yt_search_server/cors"
+)
+
+func TestCorsOptionsMapsEverySetting(t *testing.T) {
+	config, err := Load([]string{
+		"-api-keys", "key",
+		"-cors-origins", "https://app.example.org,https://*.example.com",
+		"-cors-methods", "GET,PATCH",
+		"-cors-headers", "Authorization,X-Custom",
+		"-cors-credentials", "true",
+		"-cors-max-age", "90s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := cors.Options{
+		Origins:     []string{"https://app.example.org", "https://*.example.com"},
+		Methods:     []string{"GET", "PATCH"},
+		Headers:     []string{"Authorization", "X-Custom"},
+		Credentials: true,
+		MaxAge:      90 * time.Second,
+	}
+	if got := config.CorsOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CorsOptions() = %+v, want %+v", got, want)
+	}
+
+	// A Cors setting added later must be set above and reach the options.
+	configFields := 0
+	configType := reflect.TypeOf(*config)
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		if !strings.HasPrefix(field.Name, "Cors") {
+			continue
+		}
+		configFields++
+		if reflect.ValueOf(*config).Field(i).IsZero() {
+			t.Errorf("%s is not set by this test", field.Name)
+		}
+	}
+	optionsValue := reflect.ValueOf(config.CorsOptions())
+	for i := 0; i < optionsValue.NumField(); i++ {
+		if optionsValue.Field(i).IsZero() {
+			t.Errorf("cors.Options.%s is not set from the config", optionsValue.Type().Field(i).Name)
+		}
+	}
+	if configFields != optionsValue.NumField() {
+		t.Errorf("config has %d Cors settings, cors.Options has %d fields", configFields, optionsValue.NumField())
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate keeps the test's environment and .env file out of Load.
+func isolate(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for _, setting := range settings {
+		for _, name := range setting.env {
+			t.Setenv(name, "")
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+}
+
+func writeFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	isolate(t)
+
+	file := writeFile(t, "config.json", `{
+		"apiKeys": ["file-key"],
+		"port": 1000,
+		"windowRadius": 5,
+		"storeDir": "file-store",
+		"logLevel": "debug",
+		"rateLimitCosts": {"/videos/": 20}
+	}`)
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("SERVER_PORT", "2000")
+	t.Setenv("WINDOW_RADIUS", "6")
+	t.Setenv("YOUTUBE_DATA_SERVICE_API_KEY", "single-key")
+	t.Setenv("YOUTUBE_DATA_SERVICE_API_KEYS", "env-key-1, env-key-2")
+	t.Setenv("RATE_LIMIT_COSTS", "/metrics=3")
+
+	config, err := Load([]string{"-port", "3000", "-rate-limit-costs", "/=4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"port from flags over environment and file", config.Port, 3000},
+		{"window radius from environment over file", config.WindowRadius, 6},
+		{"plural API key variable over the single one", config.ApiKeys, []string{"env-key-1", "env-key-2"}},
+		{"store directory from file", config.StoreDir, "file-store"},
+		{"log level from file", config.LogLevel, "debug"},
+		{"sample interval default", config.SampleInterval, Duration(6 * time.Hour)},
+		{"config file", config.File, file},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+
+	// Costs from each source override only the routes they name.
+	costs := config.RateLimitCosts
+	if costs["/videos/"] != 20 || costs["/metrics"] != 3 || costs["/"] != 4 || costs["/v2/channels/{id}/export"] != 10 {
+		t.Errorf("rate limit costs = %v", costs)
+	}
+
+	// The -config flag takes precedence over CONFIG_FILE.
+	other := writeFile(t, "other.yaml", "apiKeys: [other-key]\nstoreDir: other-store\n")
+	config, err = Load([]string{"-config", other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.StoreDir != "other-store" {
+		t.Errorf("store directory = %q, want other-store", config.StoreDir)
+	}
+}
+
+func TestLoadRejectsInvalidSources(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{name: "variable", env: map[string]string{"SERVER_PORT": "eighty"}, want: `invalid SERVER_PORT: "eighty" is not a number`},
+		{name: "flag", args: []string{"-sample-interval", "daily"}, want: `invalid -sample-interval: "daily" is not a duration`},
+		{name: "costs", args: []string{"-rate-limit-costs", "/videos/"}, want: `"/videos/" is not a route=cost pair`},
+		{name: "argument", args: []string{"serve"}, want: `unexpected argument "serve"`},
+		{name: "file", env: map[string]string{"CONFIG_FILE": "missing.json"}, want: "error reading config file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isolate(t)
+			t.Setenv("YOUTUBE_DATA_SERVICE_API_KEYS", "key")
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load(test.args)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Load error = %v, want it to contain %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	want := Defaults()
+	want.ApiKeys = []string{"key-1", "key-2"}
+	want.Port = 9000
+	want.SampleInterval = Duration(90 * time.Minute)
+	want.CorsCredentials = true
+	// Costs in a file are merged into the default ones.
+	want.RateLimitCosts["/videos/"] = 5
+
+	files := map[string]string{
+		"config.json": `{
+			"apiKeys": ["key-1", "key-2"],
+			"port": 9000,
+			"sampleInterval": "1h30m",
+			"corsCredentials": true,
+			"rateLimitCosts": {"/videos/": 5}
+		}`,
+		"config.toml": `
+			apiKeys = ["key-1", "key-2"]
+			port = 9000
+			sampleInterval = "1h30m"
+			corsCredentials = true
+			[rateLimitCosts]
+			"/videos/" = 5
+		`,
+		"config.yaml": `
+apiKeys: [key-1, key-2]
+port: 9000
+sampleInterval: 1h30m
+corsCredentials: true
+rateLimitCosts:
+  /videos/: 5
+`,
+	}
+
+	for name, contents := range files {
+		t.Run(name, func(t *testing.T) {
+			config := Defaults()
+			if err := readFile(writeFile(t, name, contents), config); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config, want) {
+				t.Errorf("config = %+v, want %+v", config, want)
+			}
+		})
+	}
+
+	unknown := map[string]string{
+		"unknown.json": `{"apiKeys": ["key"], "colour": "blue"}`,
+		"unknown.toml": "apiKeys = [\"key\"]\ncolour = \"blue\"\n",
+		"unknown.yaml": "apiKeys: [key]\ncolour: blue\n",
+		"unknown.yml":  "apiKeys: [key]\ncolour: blue\n",
+		"nested.toml":  "[cors]\norigins = [\"*\"]\n",
+		"typed.json":   `{"port": "9000"}`,
+		"typed.yaml":   "sampleInterval: daily\n",
+		"config.ini":   "port = 9000\n",
+	}
+	for name, contents := range unknown {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, name, contents)
+			err := readFile(path, Defaults())
+			if err == nil || !strings.Contains(err.Error(), path) {
+				t.Errorf("readFile error = %v, want an error naming the file", err)
+			}
+		})
+	}
+
+	// An empty YAML file leaves every setting alone.
+	config := Defaults()
+	if err := readFile(writeFile(t, "empty.yaml", ""), config); err != nil || !reflect.DeepEqual(config, Defaults()) {
+		t.Errorf("empty YAML file: error %v, config %+v", err, config)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(config *Config)
+		want   string
+	}{
+		{"port", func(c *Config) { c.Port = 0 }, "port must be between 1 and 65535, got 0"},
+		{"no API keys", func(c *Config) { c.ApiKeys = nil }, "at least one YouTube Data API key is required"},
+		{"empty API key", func(c *Config) { c.ApiKeys = []string{"key", " "} }, "API key 2 is empty"},
+		{"repeated API key", func(c *Config) { c.ApiKeys = []string{"key", "key"} }, "API key 2 repeats an earlier key"},
+		{"store directory", func(c *Config) { c.StoreDir = "" }, "store directory must not be empty"},
+		{"tracked channel", func(c *Config) { c.TrackedChannels = []string{"youtube.com/@x"} }, `tracked channel "youtube.com/@x" is not a channel ID`},
+		{"sample interval", func(c *Config) { c.SampleInterval = Duration(time.Second) }, "sample interval must be at least 1m0s, got 1s"},
+		{"TTL", func(c *Config) { c.VideoDetailsTTL = 0 }, "video details TTL must be at least 1s, got 0s"},
+		{"retention", func(c *Config) { c.HistoryRetention = Duration(time.Hour) }, "history retention must be 0 or at least 24h, got 1h0m0s"},
+		{"read header timeout", func(c *Config) { c.ReadHeaderTimeout = Duration(time.Minute) }, "read header timeout must not exceed the read timeout"},
+		{"max header bytes", func(c *Config) { c.MaxHeaderBytes = 100 }, "max header bytes must be at least 4096, got 100"},
+		{"window radius", func(c *Config) { c.WindowRadius = 0 }, "window radius must be between 1 and"},
+		{"CORS", func(c *Config) { c.CorsCredentials = true }, "CORS "},
+		{"auth", func(c *Config) { c.Auth = "oauth" }, `auth must be anonymous or keys, got "oauth"`},
+		{"admin token", func(c *Config) { c.AdminToken = "short" }, "admin token must be at least 32 characters long"},
+		{"rate limit", func(c *Config) { c.RateLimit = -1 }, "rate limit "},
+		{"log format", func(c *Config) { c.LogFormat = "xml" }, `log format must be text or json, got "xml"`},
+		{"log level", func(c *Config) { c.LogLevel = "loud" }, "log level "},
+	}
+
+	valid := Defaults()
+	valid.ApiKeys = []string{"key"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("defaults with a key: %s", err)
+	}
+
+	for _, test := range tests {
+		config := Defaults()
+		config.ApiKeys = []string{"key"}
+		test.change(config)
+
+		err := config.Validate()
+		if err == nil {
+			t.Errorf("%s: Validate succeeded", test.name)
+			continue
+		}
+		message := err.Error()
+		if !strings.HasPrefix(message, "invalid configuration:\n  - ") || !strings.Contains(message, test.want) {
+			t.Errorf("%s: Validate error = %q, want it to contain %q", test.name, message, test.want)
+		}
+	}
+
+	// Every problem is reported at once.
+	config := Defaults()
+	config.Port = -1
+	config.LogFormat = "xml"
+	err := config.Validate()
+	if err == nil || strings.Count(err.Error(), "\n  - ") != 3 {
+		t.Errorf("Validate error = %v, want three problems", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	config := Defaults()
+	config.ApiKeys = []string{"api-key-1", "api-key-2"}
+	config.AdminToken = strings.Repeat("a", 32)
+	config.RateLimitAllowlist = []string{"10.0.0.1", "192.168.0.0/16", "::1", "client-key"}
+
+	redactedConfig := config.Redacted()
+
+	if want := []string{redacted, redacted}; !reflect.DeepEqual(redactedConfig.ApiKeys, want) {
+		t.Errorf("ApiKeys = %q, want %q", redactedConfig.ApiKeys, want)
+	}
+	if redactedConfig.AdminToken != redacted {
+		t.Errorf("AdminToken = %q", redactedConfig.AdminToken)
+	}
+	// Addresses are not secret, client keys are.
+	want := []string{"10.0.0.1", "192.168.0.0/16", "::1", redacted}
+	if !reflect.DeepEqual(redactedConfig.RateLimitAllowlist, want) {
+		t.Errorf("RateLimitAllowlist = %q, want %q", redactedConfig.RateLimitAllowlist, want)
+	}
+	if redactedConfig.Port != config.Port || redactedConfig.StoreDir != config.StoreDir {
+		t.Errorf("other settings changed: %+v", redactedConfig)
+	}
+
+	// The original is left as it was.
+	if config.ApiKeys[0] != "api-key-1" || config.RateLimitAllowlist[3] != "client-key" || config.AdminToken == redacted {
+		t.Errorf("Redacted changed the original: %+v", config)
+	}
+
+	// An unset admin token stays visibly unset.
+	config.AdminToken = ""
+	if got := config.Redacted().AdminToken; got != "" {
+		t.Errorf("unset AdminToken = %q", got)
+	}
+}
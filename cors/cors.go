@@ -0,0 +1,249 @@
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Options struct {
+	// Origins are exact origins such as https://example.com, origins with a
+	// wildcard leftmost label such as https://*.example.com, or * for any.
+	Origins     []string
+	Methods     []string
+	Headers     []string
+	Credentials bool
+	MaxAge      time.Duration
+}
+
+// exposedHeaders are the response headers scripts on other origins may read.
+var exposedHeaders = []string{
+	"Content-Disposition",
+	"Deprecation",
+	"Link",
+	"RateLimit-Limit",
+	"RateLimit-Policy",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"Retry-After",
+	"X-Request-ID",
+}
+
+type Cors struct {
+	anyOrigin bool
+	origins   map[string]bool
+	// wildcards hold the scheme and the suffix after the *, as in
+	// https:// and .example.com.
+	wildcards [][2]string
+
+	methods     map[string]bool
+	headers     map[string]bool
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+func validateOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%q is not an origin such as https://example.com", origin)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("%q must use http or https", origin)
+	}
+	if parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" || parsed.User != nil {
+		return fmt.Errorf("%q must not have a path, query or credentials", origin)
+	}
+
+	host := parsed.Hostname()
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+		return fmt.Errorf("%q may only use * as its leftmost label, as in https://*.example.com", origin)
+	}
+
+	return nil
+}
+
+func New(options Options) (*Cors, error) {
+	problems := []string{}
+
+	if len(options.Origins) == 0 {
+		problems = append(problems, "at least one origin is required; use * to allow any")
+	}
+	for _, origin := range options.Origins {
+		if err := validateOrigin(origin); err != nil {
+			problems = append(problems, fmt.Sprintf("origin %s", err))
+		}
+		if origin == "*" && len(options.Origins) > 1 {
+			problems = append(problems, "origin * cannot be combined with other origins")
+		}
+	}
+	if options.Credentials && len(options.Origins) == 1 && options.Origins[0] == "*" {
+		problems = append(problems, "credentials cannot be allowed for any origin; list the origins")
+	}
+	if len(options.Methods) == 0 {
+		problems = append(problems, "at least one method is required")
+	}
+	for _, header := range options.Headers {
+		if header == "" || strings.ContainsAny(header, " ,:") {
+			problems = append(problems, fmt.Sprintf("%q is not a header name", header))
+		}
+	}
+	if options.MaxAge < 0 {
+		problems = append(problems, fmt.Sprintf("max age must not be negative, got %s", options.MaxAge))
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	cors := &Cors{
+		origins:       make(map[string]bool),
+		methods:       make(map[string]bool),
+		headers:       make(map[string]bool),
+		credentials:   options.Credentials,
+		exposeHeaders: strings.Join(exposedHeaders, ", "),
+		maxAge:        strconv.Itoa(int(options.MaxAge.Seconds())),
+	}
+
+	for _, origin := range options.Origins {
+		switch {
+		case origin == "*":
+			cors.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, suffix, _ := strings.Cut(strings.ToLower(origin), "*")
+			cors.wildcards = append(cors.wildcards, [2]string{scheme, suffix})
+		default:
+			cors.origins[strings.ToLower(origin)] = true
+		}
+	}
+
+	methods := []string{}
+	for _, method := range options.Methods {
+		method = strings.ToUpper(method)
+		cors.methods[method] = true
+		methods = append(methods, method)
+	}
+	cors.allowMethods = strings.Join(methods, ", ")
+
+	headers := []string{}
+	for _, header := range options.Headers {
+		cors.headers[http.CanonicalHeaderKey(header)] = true
+		headers = append(headers, header)
+	}
+	cors.allowHeaders = strings.Join(headers, ", ")
+
+	return cors, nil
+}
+
+// isSubdomain reports whether labels, the part of a host a wildcard stands
+// for, is one or more DNS labels. Anything else, such as a path or userinfo
+// smuggled in before the suffix, does not match.
+func isSubdomain(labels string) bool {
+	for _, label := range strings.Split(labels, ".") {
+		if label == "" {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (cors *Cors) allowedOrigin(origin string) bool {
+	if cors.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if cors.origins[origin] {
+		return true
+	}
+	for _, wildcard := range cors.wildcards {
+		scheme, suffix := wildcard[0], wildcard[1]
+		if strings.HasPrefix(origin, scheme) && strings.HasSuffix(origin, suffix) &&
+			len(origin) > len(scheme)+len(suffix) &&
+			isSubdomain(origin[len(scheme):len(origin)-len(suffix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cors *Cors) allowedHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !cors.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// setOrigin allows the origin to read the response. The response varies by
+// origin unless any origin is allowed without credentials.
+func (cors *Cors) setOrigin(header http.Header, origin string) {
+	if cors.anyOrigin && !cors.credentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if cors.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// Handler answers every OPTIONS request itself, so preflights never reach the
+// handlers, and adds CORS headers to the responses of other requests from
+// allowed origins.
+func (cors *Cors) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header := w.Header()
+		origin := req.Header.Get("Origin")
+
+		if req.Method == http.MethodOptions {
+			header.Add("Vary", "Origin")
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+
+			method := req.Header.Get("Access-Control-Request-Method")
+			if origin != "" && method != "" && cors.allowedOrigin(origin) &&
+				cors.methods[strings.ToUpper(method)] &&
+				cors.allowedHeaders(req.Header.Get("Access-Control-Request-Headers")) {
+				cors.setOrigin(header, origin)
+				header.Set("Access-Control-Allow-Methods", cors.allowMethods)
+				if cors.allowHeaders != "" {
+					header.Set("Access-Control-Allow-Headers", cors.allowHeaders)
+				}
+				header.Set("Access-Control-Max-Age", cors.maxAge)
+			}
+
+			// A refused preflight gets no CORS headers, which the browser
+			// reports to the page.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !cors.anyOrigin || cors.credentials {
+			header.Add("Vary", "Origin")
+		}
+		if origin != "" && cors.allowedOrigin(origin) {
+			cors.setOrigin(header, origin)
+			header.Set("Access-Control-Expose-Headers", cors.exposeHeaders)
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
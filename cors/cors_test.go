@@ -0,0 +1,240 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCors(t *testing.T, options Options) *Cors {
+	t.Helper()
+	if options.Methods == nil {
+		options.Methods = []string{"GET", "POST"}
+	}
+	cors, err := New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cors
+}
+
+func TestAllowedOrigin(t *testing.T) {
+	cors := newCors(t, Options{
+		Origins: []string{"https://app.example.org", "https://*.example.com"},
+	})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.org", true},
+		{"HTTPS://APP.EXAMPLE.ORG", true},
+		{"http://app.example.org", false},
+		{"https://app.example.org:8443", false},
+		{"https://other.example.org", false},
+
+		{"https://a.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://A-1.Example.com", true},
+		{"https://example.com", false},
+		{"https://.example.com", false},
+		{"https://a..example.com", false},
+		{"http://a.example.com", false},
+		{"https://evilexample.com", false},
+		{"https://a.example.com.evil.com", false},
+		{"https://a.example.com:8443", false},
+		{"https://evil.com/.example.com", false},
+		{"https://evil.com?.example.com", false},
+		{"https://evil.com#.example.com", false},
+		{"https://evil.com@a.example.com", false},
+		{"https://evil.com:1@a.example.com", false},
+		{`https://evil.com\.example.com`, false},
+		{"https://evil com.example.com", false},
+		{"null", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := cors.allowedOrigin(test.origin); got != test.want {
+			t.Errorf("allowedOrigin(%q) = %v, want %v", test.origin, got, test.want)
+		}
+	}
+
+	if any := newCors(t, Options{Origins: []string{"*"}}); !any.allowedOrigin("https://anything.test") {
+		t.Error("* does not allow any origin")
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	for name, options := range map[string]Options{
+		"no origins":          {Methods: []string{"GET"}},
+		"path":                {Origins: []string{"https://example.com/app"}, Methods: []string{"GET"}},
+		"scheme":              {Origins: []string{"ftp://example.com"}, Methods: []string{"GET"}},
+		"inner wildcard":      {Origins: []string{"https://a.*.example.com"}, Methods: []string{"GET"}},
+		"* with others":       {Origins: []string{"*", "https://example.com"}, Methods: []string{"GET"}},
+		"credentials for any": {Origins: []string{"*"}, Methods: []string{"GET"}, Credentials: true},
+		"no methods":          {Origins: []string{"*"}},
+		"bad header":          {Origins: []string{"*"}, Methods: []string{"GET"}, Headers: []string{"X-A, X-B"}},
+		"negative max age":    {Origins: []string{"*"}, Methods: []string{"GET"}, MaxAge: -time.Second},
+		"userinfo":            {Origins: []string{"https://user@example.com"}, Methods: []string{"GET"}},
+	} {
+		if _, err := New(options); err == nil {
+			t.Errorf("%s: New succeeded", name)
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	cors := newCors(t, Options{
+		Origins: []string{"https://app.example.org"},
+		Headers: []string{"Authorization", "Content-Type"},
+		MaxAge:  10 * time.Minute,
+	})
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("preflight reached the handler")
+	}))
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{name: "allowed", origin: "https://app.example.org", method: "POST", headers: "authorization, content-type", allowed: true},
+		{name: "lowercase method", origin: "https://app.example.org", method: "get", allowed: true},
+		{name: "other origin", origin: "https://evil.test", method: "GET"},
+		{name: "method not allowed", origin: "https://app.example.org", method: "DELETE"},
+		{name: "header not allowed", origin: "https://app.example.org", method: "GET", headers: "X-Custom"},
+		{name: "no requested method", origin: "https://app.example.org"},
+		{name: "no origin", method: "GET"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("OPTIONS", "/v2/channels/x", nil)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			if test.method != "" {
+				req.Header.Set("Access-Control-Request-Method", test.method)
+			}
+			if test.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", test.headers)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			header := w.Header()
+			if len(header.Values("Vary")) != 3 {
+				t.Errorf("Vary = %q, want Origin and the requested method and headers", header.Values("Vary"))
+			}
+
+			got := header.Get("Access-Control-Allow-Origin")
+			if !test.allowed {
+				if got != "" {
+					t.Errorf("refused preflight allows origin %q", got)
+				}
+				return
+			}
+			if got != test.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, test.origin)
+			}
+			if methods := header.Get("Access-Control-Allow-Methods"); methods != "GET, POST" {
+				t.Errorf("Access-Control-Allow-Methods = %q", methods)
+			}
+			if headers := header.Get("Access-Control-Allow-Headers"); headers != "Authorization, Content-Type" {
+				t.Errorf("Access-Control-Allow-Headers = %q", headers)
+			}
+			if maxAge := header.Get("Access-Control-Max-Age"); maxAge != "600" {
+				t.Errorf("Access-Control-Max-Age = %q, want 600", maxAge)
+			}
+		})
+	}
+}
+
+func TestActualRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     Options
+		origin      string
+		allowOrigin string
+		credentials bool
+		vary        bool
+	}{
+		{
+			name:        "any origin",
+			options:     Options{Origins: []string{"*"}},
+			origin:      "https://anything.test",
+			allowOrigin: "*",
+		},
+		{
+			name:        "listed origin",
+			options:     Options{Origins: []string{"https://app.example.org"}},
+			origin:      "https://app.example.org",
+			allowOrigin: "https://app.example.org",
+			vary:        true,
+		},
+		{
+			name:    "unlisted origin",
+			options: Options{Origins: []string{"https://app.example.org"}},
+			origin:  "https://evil.test",
+			vary:    true,
+		},
+		{
+			name:    "no origin",
+			options: Options{Origins: []string{"https://app.example.org"}},
+			vary:    true,
+		},
+		{
+			name:        "credentials",
+			options:     Options{Origins: []string{"https://*.example.com"}, Credentials: true},
+			origin:      "https://a.example.com",
+			allowOrigin: "https://a.example.com",
+			credentials: true,
+			vary:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			served := false
+			handler := newCors(t, test.options).Handler(
+				http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					served = true
+				}),
+			)
+
+			req := httptest.NewRequest("GET", "/v2/channels/x", nil)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			// CORS only decides what the page may read; the request is served.
+			if !served {
+				t.Error("request did not reach the handler")
+			}
+
+			header := w.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != test.allowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, test.allowOrigin)
+			}
+			if got := header.Get("Access-Control-Allow-Credentials") == "true"; got != test.credentials {
+				t.Errorf("credentials allowed = %v, want %v", got, test.credentials)
+			}
+			if got := header.Get("Vary") == "Origin"; got != test.vary {
+				t.Errorf("varies by origin = %v, want %v", got, test.vary)
+			}
+			exposed := header.Get("Access-Control-Expose-Headers")
+			if (test.allowOrigin != "") != (exposed != "") {
+				t.Errorf("Access-Control-Expose-Headers = %q", exposed)
+			}
+		})
+	}
+}
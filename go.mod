@@ -1,9 +1,12 @@
 module yt_search_server
 
-go 1.20
+go 1.21
 
 require (
-	github.com/gorilla/mux v1.8.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
+	github.com/BurntSushi/toml v1.3.2
+	github.com/gorilla/mux v1.8.0
+	github.com/joho/godotenv v1.5.1
 	golang.org/x/exp v0.0.0-20230905200255-921286631fa9
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+const maxRequestIdLength = 128
+
+type requestKey struct{}
+
+type request struct {
+	id            string
+	upstreamCalls atomic.Int64
+}
+
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(name))
+	if err != nil {
+		return level, fmt.Errorf("%q is not one of debug, info, warn, error", name)
+	}
+	return level, nil
+}
+
+// Setup installs the default slog logger, which the log package also writes
+// through.
+func Setup(format string, levelName string) error {
+	level, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, options)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	default:
+		return fmt.Errorf("%q is not one of text, json", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	return nil
+}
+
+func NewRequestId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// ValidRequestId accepts incoming IDs that are short and printable, so they
+// cannot break log lines or headers.
+func ValidRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool {
+		return r < 0x21 || r > 0x7e
+	}) == -1
+}
+
+func WithRequest(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{id: id})
+}
+
+func RequestId(ctx context.Context) string {
+	if request, ok := ctx.Value(requestKey{}).(*request); ok {
+		return request.id
+	}
+	return ""
+}
+
+func CountUpstreamCall(ctx context.Context) {
+	if request, ok := ctx.Value(requestKey{}).(*request); ok {
+		request.upstreamCalls.Add(1)
+	}
+}
+
+func UpstreamCalls(ctx context.Context) int64 {
+	if request, ok := ctx.Value(requestKey{}).(*request); ok {
+		return request.upstreamCalls.Load()
+	}
+	return 0
+}
+
+// FromContext returns the default logger, tagged with the request ID if ctx
+// belongs to a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestId(ctx); id != "" {
+		return slog.Default().With("requestId", id)
+	}
+	return slog.Default()
+}
@@ -1,46 +1,176 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"yt_search_server/config"
+	"yt_search_server/cors"
+	"yt_search_server/logging"
+	"yt_search_server/ratelimit"
+	"yt_search_server/sampler"
 	"yt_search_server/server"
+	"yt_search_server/store"
 	"yt_search_server/youtube"
 
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
+)
+
+const (
+	exitOk = iota
+	exitServerError
+	exitInterrupted
+	exitShutdownTimeout
+	exitFlushError
+	exitConfigError
 )
 
 func main() {
-	err := godotenv.Load()
+	config, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitOk)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(exitConfigError)
+	}
+
+	if config.PrintConfig {
+		effective, _ := json.MarshalIndent(config.Redacted(), "", "  ")
+		fmt.Println(string(effective))
+		os.Exit(exitOk)
+	}
+
+	err = logging.Setup(config.LogFormat, config.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(exitConfigError)
+	}
+
+	if config.File != "" {
+		slog.Info("Loaded configuration", "file", config.File)
+	}
+
+	youtube := youtube.NewYouTubeService(config.YouTubeOptions())
+
+	store, err := store.Open(config.StoreDir)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		slog.Error("Error opening store", "error", err)
+		os.Exit(exitServerError)
+	}
+
+	for _, channelId := range config.TrackedChannels {
+		store.Track(channelId)
 	}
 
-	youtubeApiKey := os.Getenv("YOUTUBE_DATA_SERVICE_API_KEY")
+	sampler := sampler.NewSampler(
+		youtube, store,
+		time.Duration(config.SampleInterval), time.Duration(config.HistoryRetention),
+	)
+	sampler.Start()
 
-	youtube := youtube.NewYouTubeService(youtubeApiKey)
-	server := server.NewServer(youtube)
+	server := server.NewServer(youtube, store, sampler)
+
+	crossOrigin, err := cors.New(config.CorsOptions())
+	if err != nil {
+		slog.Error("Error configuring CORS", "error", err)
+		os.Exit(exitConfigError)
+	}
+
+	limiter, err := ratelimit.NewLimiter(config.RateLimitOptions())
+	if err != nil {
+		slog.Error("Error configuring rate limits", "error", err)
+		os.Exit(exitConfigError)
+	}
 
 	router := mux.NewRouter()
 	router.StrictSlash(true)
 
-	router.HandleFunc("/", server.GetHome).Methods("GET")
-	router.HandleFunc("/metadata/", server.GetMetadata).Methods("GET", "OPTIONS")
-	router.HandleFunc("/videos/", server.GetVideos).Methods("GET", "OPTIONS")
+	server.RegisterRoutes(router)
+
+	router.Use(server.RecoverPanics)
+	router.Use(server.Authenticate(config.AuthRequired(), config.AdminToken))
+	router.Use(server.LimitRequests(limiter))
+
+	httpServer := &http.Server{
+		Addr:              config.Addr(),
+		Handler:           server.LogRequests(crossOrigin.Handler(server.MeasureRequests(router))),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		ReadTimeout:       time.Duration(config.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(config.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(config.WriteTimeout),
+		IdleTimeout:       time.Duration(config.IdleTimeout),
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening for connections", "addr", httpServer.Addr)
+		serveErr <- httpServer.ListenAndServe()
+	}()
 
-	serveUrl := os.Getenv("SERVER_HOST") + ":" + os.Getenv("SERVER_PORT")
+	select {
+	case err = <-serveErr:
+		slog.Error("Error starting server", "error", err)
+		sampler.Stop()
+		store.Flush()
+		os.Exit(exitServerError)
+	case sig := <-signals:
+		slog.Info("Shutting down", "signal", sig.String())
+	}
 
-	log.Printf("Server listening for connections at %s\n", serveUrl)
+	// A second signal skips the drain.
+	go func() {
+		sig := <-signals
+		slog.Warn("Exiting immediately", "signal", sig.String())
+		os.Exit(exitInterrupted)
+	}()
 
-	err = http.ListenAndServe(serveUrl, router)
+	ctx, cancel := context.WithTimeout(
+		context.Background(), time.Duration(config.ShutdownTimeout),
+	)
 
-	if errors.Is(err, http.ErrServerClosed) {
-		fmt.Println("Server closed.")
-	} else {
-		fmt.Printf("Error starting server: %s\n", err)
-		os.Exit(1)
+	exitCode := exitOk
+
+	err = httpServer.Shutdown(ctx)
+	if err != nil {
+		slog.Error("Error draining requests", "error", err)
+		httpServer.Close()
+		exitCode = exitShutdownTimeout
 	}
+
+	err = sampler.Shutdown(ctx)
+	if err != nil {
+		slog.Error("Error stopping sampler", "error", err)
+		exitCode = exitShutdownTimeout
+	}
+	cancel()
+
+	// Snapshots already recorded are kept even if a sampling round was cut off.
+	err = store.Flush()
+	if err != nil {
+		slog.Error("Error flushing store", "error", err)
+		exitCode = exitFlushError
+	}
+
+	if err = <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Error serving", "error", err)
+		if exitCode == exitOk {
+			exitCode = exitServerError
+		}
+	}
+
+	slog.Info("Server closed")
+	os.Exit(exitCode)
 }
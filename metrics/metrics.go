@@ -0,0 +1,350 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DurationBuckets suit latencies in seconds, from 5ms to a minute.
+var DurationBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60,
+}
+
+type family interface {
+	write(w *bufio.Writer)
+}
+
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+var Default = NewRegistry()
+
+func (registry *Registry) register(name string, family family) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.names[name] {
+		panic(fmt.Sprintf("metric %s registered twice", name))
+	}
+	registry.names[name] = true
+	registry.families = append(registry.families, family)
+}
+
+// WriteText writes every metric in the Prometheus text exposition format.
+func (registry *Registry) WriteText(w io.Writer) error {
+	registry.mu.Lock()
+	families := append([]family{}, registry.families...)
+	registry.mu.Unlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, family := range families {
+		family.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		registry.WriteText(w)
+	})
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func formatLabels(names []string, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(names)+len(extra)/2)
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelValueEscaper.Replace(values[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], labelValueEscaper.Replace(extra[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func writeHeader(w *bufio.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, kind)
+}
+
+// vec holds one series per combination of label values.
+type vec[S any] struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*S
+	values map[string][]string
+	create func() *S
+}
+
+func newVec[S any](name string, help string, labels []string, create func() *S) *vec[S] {
+	return &vec[S]{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*S),
+		values: make(map[string][]string),
+		create: create,
+	}
+}
+
+func (vec *vec[S]) with(values []string) *S {
+	if len(values) != len(vec.labels) {
+		panic(fmt.Sprintf(
+			"metric %s takes %d label values, got %d", vec.name, len(vec.labels), len(values),
+		))
+	}
+
+	key := strings.Join(values, "\xff")
+
+	vec.mu.Lock()
+	defer vec.mu.Unlock()
+
+	series, ok := vec.series[key]
+	if !ok {
+		series = vec.create()
+		vec.series[key] = series
+		vec.values[key] = append([]string{}, values...)
+	}
+	return series
+}
+
+func (vec *vec[S]) each(fn func(values []string, series *S)) {
+	vec.mu.Lock()
+	keys := make([]string, 0, len(vec.series))
+	for key := range vec.series {
+		keys = append(keys, key)
+	}
+	vec.mu.Unlock()
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		vec.mu.Lock()
+		series, values := vec.series[key], vec.values[key]
+		vec.mu.Unlock()
+		fn(values, series)
+	}
+}
+
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (counter *Counter) Add(value float64) {
+	if value < 0 {
+		panic("counters cannot decrease")
+	}
+	counter.mu.Lock()
+	counter.value += value
+	counter.mu.Unlock()
+}
+
+func (counter *Counter) Inc() {
+	counter.Add(1)
+}
+
+func (counter *Counter) get() float64 {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	return counter.value
+}
+
+type CounterVec struct {
+	*vec[Counter]
+}
+
+func (registry *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	counters := &CounterVec{newVec(name, help, labels, func() *Counter { return &Counter{} })}
+	registry.register(name, counters)
+	return counters
+}
+
+func (counters *CounterVec) With(values ...string) *Counter {
+	return counters.with(values)
+}
+
+func (counters *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, counters.name, counters.help, "counter")
+	counters.each(func(values []string, counter *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n",
+			counters.name, formatLabels(counters.labels, values), formatValue(counter.get()))
+	})
+}
+
+type Gauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (gauge *Gauge) Add(value float64) {
+	gauge.mu.Lock()
+	gauge.value += value
+	gauge.mu.Unlock()
+}
+
+func (gauge *Gauge) Inc() {
+	gauge.Add(1)
+}
+
+func (gauge *Gauge) Dec() {
+	gauge.Add(-1)
+}
+
+func (gauge *Gauge) Set(value float64) {
+	gauge.mu.Lock()
+	gauge.value = value
+	gauge.mu.Unlock()
+}
+
+func (gauge *Gauge) get() float64 {
+	gauge.mu.Lock()
+	defer gauge.mu.Unlock()
+	return gauge.value
+}
+
+type GaugeVec struct {
+	*vec[Gauge]
+}
+
+func (registry *Registry) NewGaugeVec(name string, help string, labels ...string) *GaugeVec {
+	gauges := &GaugeVec{newVec(name, help, labels, func() *Gauge { return &Gauge{} })}
+	registry.register(name, gauges)
+	return gauges
+}
+
+func (gauges *GaugeVec) With(values ...string) *Gauge {
+	return gauges.with(values)
+}
+
+func (gauges *GaugeVec) write(w *bufio.Writer) {
+	writeHeader(w, gauges.name, gauges.help, "gauge")
+	gauges.each(func(values []string, gauge *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n",
+			gauges.name, formatLabels(gauges.labels, values), formatValue(gauge.get()))
+	})
+}
+
+// A gaugeFunc is read when the metrics are scraped.
+type gaugeFunc struct {
+	name  string
+	help  string
+	value func() float64
+}
+
+func (registry *Registry) NewGaugeFunc(name string, help string, value func() float64) {
+	registry.register(name, &gaugeFunc{name: name, help: help, value: value})
+}
+
+func (gauge *gaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, gauge.name, gauge.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", gauge.name, formatValue(gauge.value()))
+}
+
+type Histogram struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (histogram *Histogram) Observe(value float64) {
+	histogram.mu.Lock()
+	defer histogram.mu.Unlock()
+
+	for i, bound := range histogram.buckets {
+		if value <= bound {
+			histogram.counts[i]++
+			break
+		}
+	}
+	histogram.sum += value
+	histogram.count++
+}
+
+type HistogramVec struct {
+	*vec[Histogram]
+	buckets []float64
+}
+
+func (registry *Registry) NewHistogramVec(
+	name string, help string, buckets []float64, labels ...string,
+) *HistogramVec {
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+
+	histograms := &HistogramVec{
+		vec: newVec(name, help, labels, func() *Histogram {
+			return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+		}),
+		buckets: buckets,
+	}
+	registry.register(name, histograms)
+	return histograms
+}
+
+func (histograms *HistogramVec) With(values ...string) *Histogram {
+	return histograms.with(values)
+}
+
+func (histograms *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, histograms.name, histograms.help, "histogram")
+	histograms.each(func(values []string, histogram *Histogram) {
+		histogram.mu.Lock()
+		counts := append([]uint64{}, histogram.counts...)
+		sum, count := histogram.sum, histogram.count
+		histogram.mu.Unlock()
+
+		cumulative := uint64(0)
+		for i, bound := range histograms.buckets {
+			cumulative += counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n",
+				histograms.name,
+				formatLabels(histograms.labels, values, "le", formatValue(bound)),
+				cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n",
+			histograms.name, formatLabels(histograms.labels, values, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n",
+			histograms.name, formatLabels(histograms.labels, values), formatValue(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n",
+			histograms.name, formatLabels(histograms.labels, values), count)
+	})
+}
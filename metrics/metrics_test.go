@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// sampleLine is a sample in the text exposition format: a metric name, label
+// pairs whose values escape backslash, double quote and newline, and a value.
+var sampleLine = regexp.MustCompile(
+	`^[a-zA-Z_:][a-zA-Z0-9_:]*` +
+		`(\{[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*")*\})?` +
+		` (?:[-+]?[0-9.eE+-]+|\+Inf|-Inf|NaN)$`,
+)
+
+var commentLine = regexp.MustCompile(`^# (HELP|TYPE) [a-zA-Z_:][a-zA-Z0-9_:]* .*$`)
+
+// scrape fetches the registry through its handler and checks that every line
+// is a comment or a sample.
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+
+	body := w.Body.String()
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		t.Errorf("exposition does not end with a newline")
+	}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !sampleLine.MatchString(line) && !commentLine.MatchString(line) {
+			t.Errorf("line is not in the text format: %q", line)
+		}
+	}
+	return body
+}
+
+func TestCounterExposition(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("requests_total", "Requests by path.\nAnd a second line.", "path", "method")
+
+	requests.With("/b", "GET").Inc()
+	requests.With("/a", "GET").Add(2.5)
+	requests.With("/a", "GET").Inc()
+	requests.With(`C:\dir "quoted"`+"\nnext", "POST").Inc()
+
+	got := scrape(t, registry)
+	want := `# HELP requests_total Requests by path.\nAnd a second line.
+# TYPE requests_total counter
+requests_total{path="/a",method="GET"} 3.5
+requests_total{path="/b",method="GET"} 1
+requests_total{path="C:\\dir \"quoted\"\nnext",method="POST"} 1
+`
+	if got != want {
+		t.Errorf("exposition =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramExposition(t *testing.T) {
+	registry := NewRegistry()
+	durations := registry.NewHistogramVec("duration_seconds", "Durations.", []float64{1, 0.5}, "route")
+
+	for _, value := range []float64{0.3, 0.5, 0.7, 5} {
+		durations.With(`/a"b`).Observe(value)
+	}
+
+	got := scrape(t, registry)
+	want := `# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/a\"b",le="0.5"} 2
+duration_seconds_bucket{route="/a\"b",le="1"} 3
+duration_seconds_bucket{route="/a\"b",le="+Inf"} 4
+duration_seconds_sum{route="/a\"b"} 6.5
+duration_seconds_count{route="/a\"b"} 4
+`
+	if got != want {
+		t.Errorf("exposition =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGaugeExposition(t *testing.T) {
+	registry := NewRegistry()
+	inFlight := registry.NewGaugeVec("in_flight", "In flight.")
+	registry.NewGaugeFunc("ratio", "A ratio.", func() float64 { return math.Inf(1) })
+
+	inFlight.With().Inc()
+	inFlight.With().Inc()
+	inFlight.With().Dec()
+
+	got := scrape(t, registry)
+	want := `# HELP in_flight In flight.
+# TYPE in_flight gauge
+in_flight 1
+# HELP ratio A ratio.
+# TYPE ratio gauge
+ratio +Inf
+`
+	if got != want {
+		t.Errorf("exposition =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("requests_total", "Requests.")
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a name twice did not panic")
+		}
+	}()
+	registry.NewGaugeVec("requests_total", "Requests.")
+}
+
+func TestWithChecksLabelCount(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounterVec("requests_total", "Requests.", "path")
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("With accepted the wrong number of label values")
+		}
+	}()
+	requests.With("/a", "GET")
+}
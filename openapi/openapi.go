@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Spec is the OpenAPI 3 document describing the server's API. It is kept by
+// hand, and Check holds it to the routes the server registers.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Docs is a page rendering Spec, which it loads from openapi.json next to it.
+//
+//go:embed docs.html
+var Docs []byte
+
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)`)
+
+type parameter struct {
+	Ref  string `json:"$ref"`
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+type operation struct {
+	Parameters []parameter `json:"parameters"`
+}
+
+type document struct {
+	// Path items also hold fields other than operations, so they are decoded
+	// one method at a time.
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Parameters map[string]parameter `json:"parameters"`
+	} `json:"components"`
+}
+
+func (doc *document) resolve(param parameter) (parameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	name := strings.TrimPrefix(param.Ref, "#/components/parameters/")
+	resolved, ok := doc.Components.Parameters[name]
+	if !ok {
+		return param, fmt.Errorf("parameter %s is not defined", param.Ref)
+	}
+	return resolved, nil
+}
+
+func routeParams(template string) []string {
+	names := []string{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(template, -1) {
+		names = append(names, match[1])
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check reports the ways the routes registered on router and the operations
+// in Spec disagree: routes that are not documented, documented operations
+// that are not served, and path parameters that differ between the two.
+func Check(router *mux.Router) error {
+	return check(router, Spec)
+}
+
+func check(router *mux.Router, spec []byte) error {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+
+	served := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			served[strings.ToLower(method)+" "+template] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	problems := []string{}
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for _, method := range operationMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return fmt.Errorf("parsing %s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			key := method + " " + path
+			documented[key] = true
+			if !served[key] {
+				problems = append(problems, fmt.Sprintf(
+					"%s %s is documented but not served", strings.ToUpper(method), path,
+				))
+				continue
+			}
+
+			names := []string{}
+			for _, param := range op.Parameters {
+				param, err := doc.resolve(param)
+				if err != nil {
+					problems = append(problems, fmt.Sprintf(
+						"%s %s: %s", strings.ToUpper(method), path, err,
+					))
+					continue
+				}
+				if param.In == "path" {
+					names = append(names, param.Name)
+				}
+			}
+			sort.Strings(names)
+
+			if want := routeParams(path); strings.Join(names, ",") != strings.Join(want, ",") {
+				problems = append(problems, fmt.Sprintf(
+					"%s %s documents path parameters [%s], expected [%s]",
+					strings.ToUpper(method), path,
+					strings.Join(names, ", "), strings.Join(want, ", "),
+				))
+			}
+		}
+	}
+
+	for key := range served {
+		if !documented[key] {
+			method, path, _ := strings.Cut(key, " ")
+			problems = append(problems, fmt.Sprintf(
+				"%s %s is served but not documented", strings.ToUpper(method), path,
+			))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
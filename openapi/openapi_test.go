@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSpecIsValidJson(t *testing.T) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %s", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v, want 3.0.3", doc["openapi"])
+	}
+}
+
+func TestCheckReportsDrift(t *testing.T) {
+	handler := func(w http.ResponseWriter, req *http.Request) {}
+
+	var doc document
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// A router serving exactly the documented operations.
+	full := func() *mux.Router {
+		router := mux.NewRouter()
+		for path, item := range doc.Paths {
+			for _, method := range operationMethods {
+				if _, ok := item[method]; ok {
+					router.HandleFunc(path, handler).Methods(strings.ToUpper(method))
+				}
+			}
+		}
+		return router
+	}
+
+	if err := Check(full()); err != nil {
+		t.Fatalf("Check of the documented routes = %s, want nil", err)
+	}
+
+	tests := []struct {
+		name  string
+		route func(router *mux.Router)
+		want  string
+	}{
+		{
+			name: "undocumented route",
+			route: func(router *mux.Router) {
+				router.HandleFunc("/v2/undocumented", handler).Methods("GET")
+			},
+			want: "GET /v2/undocumented is served but not documented",
+		},
+		{
+			name: "undocumented method",
+			route: func(router *mux.Router) {
+				router.HandleFunc("/v2/tracked", handler).Methods("POST")
+			},
+			want: "POST /v2/tracked is served but not documented",
+		},
+		{
+			name: "renamed path parameter",
+			route: func(router *mux.Router) {
+				router.HandleFunc("/v2/videos/{videoId}", handler).Methods("GET")
+			},
+			want: "GET /v2/videos/{videoId} is served but not documented",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := full()
+			test.route(router)
+
+			err := Check(router)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Check = %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+
+	t.Run("mismatched path parameters", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.HandleFunc("/items/{id}", handler).Methods("GET")
+
+		spec := []byte(`{"paths": {"/items/{id}": {"get": {"parameters": [
+			{"name": "itemId", "in": "path"}
+		]}}}}`)
+		err := check(router, spec)
+		want := "GET /items/{id} documents path parameters [itemId], expected [id]"
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("check = %v, want an error containing %q", err, want)
+		}
+	})
+
+	t.Run("undefined parameter reference", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.HandleFunc("/items", handler).Methods("GET")
+
+		spec := []byte(`{"paths": {"/items": {"get": {"parameters": [
+			{"$ref": "#/components/parameters/missing"}
+		]}}}}`)
+		err := check(router, spec)
+		want := "parameter #/components/parameters/missing is not defined"
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("check = %v, want an error containing %q", err, want)
+		}
+	})
+
+	t.Run("unserved operation", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.HandleFunc("/", handler).Methods("GET")
+
+		err := Check(router)
+		want := "GET /healthz is documented but not served"
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Check = %v, want an error containing %q", err, want)
+		}
+	})
+}
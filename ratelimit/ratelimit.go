@@ -0,0 +1,285 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Buckets that have refilled completely hold no state worth keeping, and are
+// dropped this often.
+const pruneInterval = time.Minute
+
+type Options struct {
+	// PerMinute is the rate at which a client's tokens refill. Zero disables
+	// limiting.
+	PerMinute float64
+	// Burst is the most tokens a client can hold, and so the costliest run of
+	// requests it can make at once.
+	Burst int
+	// Costs maps route templates, such as /v2/videos/{id}, to the tokens a
+	// request to them takes. Other routes cost DefaultCost.
+	Costs       map[string]int
+	DefaultCost int
+	// Allowlist holds IP addresses, CIDR ranges and client keys that are
+	// never limited.
+	Allowlist []string
+	// TrustedProxies are CIDR ranges whose X-Forwarded-For header is believed.
+	TrustedProxies []string
+}
+
+// An Allowance replaces the limiter's rate and burst for one client. Zero
+// fields keep the limiter's own. An allowance with a rate limits its client
+// even when the limiter itself is off, with a burst of a minute's tokens
+// unless it gives one.
+type Allowance struct {
+	PerMinute float64
+	Burst     int
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	rate    float64
+	burst   float64
+}
+
+type Limiter struct {
+	rate        float64
+	burst       float64
+	costs       map[string]int
+	defaultCost int
+
+	allowedNets    []*net.IPNet
+	allowedKeys    map[[sha256.Size]byte]bool
+	trustedProxies []*net.IPNet
+
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	lastPruned time.Time
+}
+
+// A Decision reports the state of a client's bucket after a request.
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until a rejected request could succeed.
+	RetryAfter time.Duration
+	// Window is the time an empty bucket takes to refill.
+	Window time.Duration
+}
+
+func parseNet(entry string) (*net.IPNet, bool) {
+	if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+		return ipNet, true
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, false
+	}
+	bits := 8 * net.IPv6len
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, true
+}
+
+func NewLimiter(options Options) (*Limiter, error) {
+	limiter := &Limiter{
+		rate:        options.PerMinute / 60,
+		burst:       float64(options.Burst),
+		costs:       options.Costs,
+		defaultCost: options.DefaultCost,
+		allowedKeys: make(map[[sha256.Size]byte]bool),
+		buckets:     make(map[string]*bucket),
+	}
+
+	if options.PerMinute < 0 {
+		return nil, fmt.Errorf("rate must not be negative, got %g", options.PerMinute)
+	}
+	// Burst and costs only matter while limiting is on.
+	if options.PerMinute > 0 {
+		if options.Burst < 1 {
+			return nil, fmt.Errorf("burst must be at least 1, got %d", options.Burst)
+		}
+		if options.DefaultCost < 0 || options.DefaultCost > options.Burst {
+			return nil, fmt.Errorf(
+				"default cost must be between 0 and the burst of %d, got %d",
+				options.Burst, options.DefaultCost,
+			)
+		}
+		for route, cost := range options.Costs {
+			if cost < 0 || cost > options.Burst {
+				return nil, fmt.Errorf(
+					"cost of %s must be between 0 and the burst of %d, got %d",
+					route, options.Burst, cost,
+				)
+			}
+		}
+	}
+
+	for _, entry := range options.Allowlist {
+		if ipNet, ok := parseNet(entry); ok {
+			limiter.allowedNets = append(limiter.allowedNets, ipNet)
+		} else {
+			limiter.allowedKeys[sha256.Sum256([]byte(entry))] = true
+		}
+	}
+
+	for _, entry := range options.TrustedProxies {
+		ipNet, ok := parseNet(entry)
+		if !ok {
+			return nil, fmt.Errorf("trusted proxy %q is not an IP address or CIDR range", entry)
+		}
+		limiter.trustedProxies = append(limiter.trustedProxies, ipNet)
+	}
+
+	return limiter, nil
+}
+
+func (limiter *Limiter) Enabled() bool {
+	return limiter.rate > 0
+}
+
+// Limits reports whether a client with the allowance is limited at all.
+func (limiter *Limiter) Limits(allowance Allowance) bool {
+	return limiter.Enabled() || allowance.PerMinute > 0
+}
+
+func (limiter *Limiter) Cost(route string) int {
+	if cost, ok := limiter.costs[route]; ok {
+		return cost
+	}
+	return limiter.defaultCost
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIp returns the address of the client, looking through trusted proxies
+// to the last address in X-Forwarded-For that none of them added.
+func (limiter *Limiter) ClientIp(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !contains(limiter.trustedProxies, ip) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if hop == nil {
+			break
+		}
+		host = hop.String()
+		if !contains(limiter.trustedProxies, hop) {
+			break
+		}
+	}
+	return host
+}
+
+// Allowlisted reports whether the client's address or the key it presented,
+// if any, is on the allowlist.
+func (limiter *Limiter) Allowlisted(clientIp string, key string) bool {
+	if ip := net.ParseIP(clientIp); ip != nil && contains(limiter.allowedNets, ip) {
+		return true
+	}
+	return key != "" && limiter.allowedKeys[sha256.Sum256([]byte(key))]
+}
+
+// Take spends cost tokens from the client's bucket if it holds enough.
+func (limiter *Limiter) Take(client string, cost int, now time.Time) Decision {
+	return limiter.TakeAllowance(client, cost, Allowance{}, now)
+}
+
+// TakeAllowance is Take for a client with its own allowance. A cost larger
+// than the allowance's burst takes the whole bucket rather than never
+// succeeding.
+func (limiter *Limiter) TakeAllowance(
+	client string, cost int, allowance Allowance, now time.Time,
+) Decision {
+	rate, burst := limiter.rate, limiter.burst
+	if allowance.PerMinute > 0 {
+		rate = allowance.PerMinute / 60
+	}
+	if allowance.Burst > 0 {
+		burst = float64(allowance.Burst)
+	} else if burst == 0 {
+		burst = math.Max(1, math.Ceil(allowance.PerMinute))
+	}
+	if rate == 0 {
+		return Decision{Allowed: true}
+	}
+	need := math.Min(float64(cost), burst)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if now.Sub(limiter.lastPruned) > pruneInterval {
+		limiter.prune(now)
+	}
+
+	b, ok := limiter.buckets[client]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		limiter.buckets[client] = b
+	}
+	b.rate, b.burst = rate, burst
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	decision := Decision{Limit: int(burst), Window: refillTime(burst, rate)}
+
+	if b.tokens >= need {
+		b.tokens -= need
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = refillTime(need-b.tokens, rate)
+	}
+
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = refillTime(burst-b.tokens, rate)
+
+	return decision
+}
+
+func refillTime(tokens float64, rate float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / rate * float64(time.Second)))
+}
+
+func (limiter *Limiter) prune(now time.Time) {
+	for client, b := range limiter.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.rate >= b.burst {
+			delete(limiter.buckets, client)
+		}
+	}
+	limiter.lastPruned = now
+}
+
+// IsAddress reports whether an allowlist entry is an IP address or CIDR range
+// rather than a client key.
+func IsAddress(entry string) bool {
+	_, ok := parseNet(entry)
+	return ok
+}
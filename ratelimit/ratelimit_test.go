@@ -0,0 +1,240 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newLimiter(t *testing.T, options Options) *Limiter {
+	t.Helper()
+	limiter, err := NewLimiter(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return limiter
+}
+
+func TestClientIp(t *testing.T) {
+	limiter := newLimiter(t, Options{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{
+			name:       "untrusted peer's header ignored",
+			remoteAddr: "203.0.113.7:1234",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.1.2.3:1234",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "chain of trusted proxies",
+			remoteAddr: "10.1.2.3:1234",
+			forwarded:  []string{"198.51.100.1, 192.168.1.1, 10.9.9.9"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed entries before the client",
+			remoteAddr: "10.1.2.3:1234",
+			forwarded:  []string{"1.1.1.1, 198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "several headers",
+			remoteAddr: "10.1.2.3:1234",
+			forwarded:  []string{"1.1.1.1", "198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "garbage stops the walk",
+			remoteAddr: "10.1.2.3:1234",
+			forwarded:  []string{"198.51.100.1, not-an-ip, 10.9.9.9"},
+			want:       "10.9.9.9",
+		},
+		{
+			name:       "proxy without header",
+			remoteAddr: "10.1.2.3:1234",
+			want:       "10.1.2.3",
+		},
+		{name: "IPv6", remoteAddr: "[2001:db8::1]:1234", want: "2001:db8::1"},
+		{name: "no port", remoteAddr: "203.0.113.7", want: "203.0.113.7"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			for _, value := range test.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if got := limiter.ClientIp(req); got != test.want {
+				t.Errorf("ClientIp = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAllowlisted(t *testing.T) {
+	limiter := newLimiter(t, Options{
+		Allowlist: []string{"10.0.0.0/8", "203.0.113.7", "2001:db8::/32", "yts_trusted"},
+	})
+
+	tests := []struct {
+		name     string
+		clientIp string
+		key      string
+		want     bool
+	}{
+		{name: "in range", clientIp: "10.200.0.1", want: true},
+		{name: "exact address", clientIp: "203.0.113.7", want: true},
+		{name: "neighbouring address", clientIp: "203.0.113.8", want: false},
+		{name: "IPv6 range", clientIp: "2001:db8::42", want: true},
+		{name: "key", clientIp: "198.51.100.1", key: "yts_trusted", want: true},
+		{name: "other key", clientIp: "198.51.100.1", key: "yts_other", want: false},
+		{name: "no key", clientIp: "198.51.100.1", want: false},
+		{name: "address given as key", clientIp: "198.51.100.1", key: "203.0.113.7", want: false},
+		{name: "unparseable address", clientIp: "unknown", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := limiter.Allowlisted(test.clientIp, test.key); got != test.want {
+				t.Errorf("Allowlisted(%q, %q) = %v, want %v", test.clientIp, test.key, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTake(t *testing.T) {
+	// A token a second, and at most 3 at once.
+	limiter := newLimiter(t, Options{PerMinute: 60, Burst: 3, DefaultCost: 1})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name      string
+		after     time.Duration
+		cost      int
+		allowed   bool
+		remaining int
+	}{
+		{name: "full bucket", cost: 1, allowed: true, remaining: 2},
+		{name: "spend the rest", cost: 2, allowed: true, remaining: 0},
+		{name: "empty", cost: 1, allowed: false, remaining: 0},
+		{name: "refilled one", after: time.Second, cost: 1, allowed: true, remaining: 0},
+		{name: "refill is capped", after: time.Hour, cost: 0, allowed: true, remaining: 3},
+		{name: "cost above burst takes the bucket", cost: 10, allowed: true, remaining: 0},
+	}
+
+	now := start
+	for _, step := range steps {
+		now = now.Add(step.after)
+		decision := limiter.Take("client", step.cost, now)
+		if decision.Allowed != step.allowed || decision.Remaining != step.remaining {
+			t.Errorf("%s: allowed %v with %d remaining, want %v with %d",
+				step.name, decision.Allowed, decision.Remaining, step.allowed, step.remaining)
+		}
+		if decision.Limit != 3 || decision.Window != 3*time.Second {
+			t.Errorf("%s: limit %d over %s, want 3 over 3s", step.name, decision.Limit, decision.Window)
+		}
+	}
+
+	// Clients have their own buckets.
+	if decision := limiter.Take("other", 3, now); !decision.Allowed {
+		t.Error("another client was limited by the first one's bucket")
+	}
+
+	decision := limiter.Take("client", 1, now)
+	if decision.Allowed || decision.RetryAfter != time.Second {
+		t.Errorf("rejected decision = %+v, want retry after 1s", decision)
+	}
+}
+
+func TestTakeAllowance(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		options   Options
+		allowance Allowance
+		// allowed is how many requests of cost 1 in a row succeed.
+		allowed   int
+		unlimited bool
+	}{
+		{
+			name:    "limiter's own",
+			options: Options{PerMinute: 60, Burst: 3, DefaultCost: 1},
+			allowed: 3,
+		},
+		{
+			name:      "larger burst",
+			options:   Options{PerMinute: 60, Burst: 3, DefaultCost: 1},
+			allowance: Allowance{Burst: 5},
+			allowed:   5,
+		},
+		{
+			name:      "rate with the limiter off takes a minute's burst",
+			options:   Options{},
+			allowance: Allowance{PerMinute: 4},
+			allowed:   4,
+		},
+		{
+			name:      "rate and burst with the limiter off",
+			options:   Options{},
+			allowance: Allowance{PerMinute: 4, Burst: 2},
+			allowed:   2,
+		},
+		{
+			name:      "limiter off and no rate",
+			options:   Options{},
+			unlimited: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limiter := newLimiter(t, test.options)
+			if got := limiter.Limits(test.allowance); got == test.unlimited {
+				t.Errorf("Limits = %v, want %v", got, !test.unlimited)
+			}
+
+			allowed := 0
+			for i := 0; i < 10; i++ {
+				if limiter.TakeAllowance("client", 1, test.allowance, now).Allowed {
+					allowed++
+				}
+			}
+			want := test.allowed
+			if test.unlimited {
+				want = 10
+			}
+			if allowed != want {
+				t.Errorf("%d of 10 requests allowed, want %d", allowed, want)
+			}
+		})
+	}
+}
+
+func TestNewLimiterRejectsInvalidOptions(t *testing.T) {
+	for name, options := range map[string]Options{
+		"negative rate":       {PerMinute: -1},
+		"no burst":            {PerMinute: 60},
+		"cost above burst":    {PerMinute: 60, Burst: 2, DefaultCost: 3},
+		"route above burst":   {PerMinute: 60, Burst: 2, DefaultCost: 1, Costs: map[string]int{"/x": 3}},
+		"untrusted proxy":     {TrustedProxies: []string{"proxy.example.com"}},
+		"negative route cost": {PerMinute: 60, Burst: 2, DefaultCost: 1, Costs: map[string]int{"/x": -1}},
+	} {
+		if _, err := NewLimiter(options); err == nil {
+			t.Errorf("%s: NewLimiter succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package sampler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"yt_search_server/store"
+	"yt_search_server/youtube"
+)
+
+type Sampler struct {
+	youtube  *youtube.YouTube
+	store    *store.Store
+	interval time.Duration
+	// retention is how long every snapshot is kept; zero keeps all of them.
+	retention time.Duration
+
+	// ctx is cancelled on Stop, abandoning the upstream calls in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu orders SampleNow after Stop, so no job starts once Stop waits.
+	mu       sync.Mutex
+	stopping bool
+	stop     chan struct{}
+	jobs     sync.WaitGroup
+}
+
+func NewSampler(
+	youtube *youtube.YouTube,
+	store *store.Store,
+	interval time.Duration,
+	retention time.Duration,
+) *Sampler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Sampler{
+		youtube:   youtube,
+		store:     store,
+		interval:  interval,
+		retention: retention,
+		ctx:       ctx,
+		cancel:    cancel,
+		stop:      make(chan struct{}),
+	}
+}
+
+func (sampler *Sampler) Start() {
+	sampler.jobs.Add(1)
+	go func() {
+		defer sampler.jobs.Done()
+
+		ticker := time.NewTicker(sampler.interval)
+		defer ticker.Stop()
+
+		for {
+			sampler.sampleTracked()
+
+			select {
+			case <-ticker.C:
+			case <-sampler.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop cuts short the sampling in progress, if any, and waits for it to wind
+// down. Snapshots recorded before then are kept.
+func (sampler *Sampler) Stop() {
+	sampler.mu.Lock()
+	if !sampler.stopping {
+		sampler.stopping = true
+		close(sampler.stop)
+		sampler.cancel()
+	}
+	sampler.mu.Unlock()
+
+	sampler.jobs.Wait()
+}
+
+// Shutdown stops the sampler like Stop, but gives up waiting when ctx is done.
+func (sampler *Sampler) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		sampler.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SampleNow samples the channel in the background, unless the sampler is
+// stopping.
+func (sampler *Sampler) SampleNow(channelId string) {
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+
+	if sampler.stopping {
+		return
+	}
+
+	sampler.jobs.Add(1)
+	go func() {
+		defer sampler.jobs.Done()
+
+		sampler.sample(channelId)
+
+		err := sampler.store.Flush()
+		if err != nil {
+			slog.Error("Error flushing store", "error", err)
+		}
+	}()
+}
+
+func (sampler *Sampler) sampleTracked() {
+	for _, channelId := range sampler.store.TrackedChannels() {
+		select {
+		case <-sampler.stop:
+			return
+		default:
+		}
+
+		sampler.sample(channelId)
+	}
+
+	if sampler.retention > 0 {
+		dropped := sampler.store.Thin(time.Now().Add(-sampler.retention))
+		if dropped > 0 {
+			slog.Info("Thinned old snapshots", "dropped", dropped)
+		}
+	}
+
+	err := sampler.store.Flush()
+	if err != nil {
+		slog.Error("Error flushing store", "error", err)
+	}
+}
+
+// sample samples the channel, logging failures other than being stopped.
+func (sampler *Sampler) sample(channelId string) {
+	err := sampler.SampleChannel(channelId)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error("Error sampling channel", "channelId", channelId, "error", err)
+	}
+}
+
+func (sampler *Sampler) SampleChannel(channelId string) error {
+	sampledAt := time.Now().UTC()
+	client := sampler.youtube.WithContext(sampler.ctx)
+
+	channel, err := client.GetChannel(channelId)
+	if err != nil {
+		return err
+	}
+
+	channelSnapshot := store.ChannelSnapshot{Time: sampledAt}
+
+	channelSnapshot.SubscriberCount, err = youtube.ParseOptionalCount(channel.SubscriberCount)
+	if err != nil {
+		return fmt.Errorf("invalid subscriber count %q", channel.SubscriberCount)
+	}
+	channelSnapshot.ViewCount, err = youtube.ParseCount(channel.ViewCount)
+	if err != nil {
+		return fmt.Errorf("invalid view count %q", channel.ViewCount)
+	}
+	channelSnapshot.VideoCount, err = youtube.ParseCount(channel.VideoCount)
+	if err != nil {
+		return fmt.Errorf("invalid video count %q", channel.VideoCount)
+	}
+
+	sampler.store.AddChannelSnapshot(channelId, channelSnapshot)
+
+	videos, err := client.GetTimeline(channelId, youtube.ChannelVideosOptions{})
+	if err != nil {
+		return err
+	}
+
+	videoIds := make([]string, len(videos))
+	for i, video := range videos {
+		videoIds[i] = video.VideoId
+	}
+
+	statistics, err := client.GetVideoStatistics(videoIds)
+	if err != nil {
+		return err
+	}
+
+	for _, videoId := range videoIds {
+		videoStatistics, ok := statistics[videoId]
+		// Videos that have not premiered yet have no views to record.
+		if !ok || videoStatistics.ViewCount == "" {
+			continue
+		}
+
+		videoSnapshot := store.VideoSnapshot{Time: sampledAt}
+
+		videoSnapshot.ViewCount, err = youtube.ParseCount(videoStatistics.ViewCount)
+		if err != nil {
+			return fmt.Errorf("invalid view count %q for %s", videoStatistics.ViewCount, videoId)
+		}
+		videoSnapshot.LikeCount, err = youtube.ParseOptionalCount(videoStatistics.LikeCount)
+		if err != nil {
+			return fmt.Errorf("invalid like count %q for %s", videoStatistics.LikeCount, videoId)
+		}
+		videoSnapshot.CommentCount, err = youtube.ParseOptionalCount(videoStatistics.CommentCount)
+		if err != nil {
+			return fmt.Errorf("invalid comment count %q for %s", videoStatistics.CommentCount, videoId)
+		}
+
+		sampler.store.AddVideoSnapshot(videoId, videoSnapshot)
+	}
+
+	return nil
+}
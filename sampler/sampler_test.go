@@ -0,0 +1,64 @@
+package sampler
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+	"yt_search_server/store"
+	"yt_search_server/youtube"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestStopCancelsSampling(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+
+	transport := http.DefaultClient.Transport
+	// Every upstream call hangs until its request is abandoned.
+	http.DefaultClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+
+	snapshots, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampler := NewSampler(
+		youtube.NewYouTubeService(youtube.Options{ApiKeys: []string{"key"}}),
+		snapshots, time.Hour, 0,
+	)
+
+	sampler.SampleNow("UCchannel")
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		sampler.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not cut short the sampling in progress")
+	}
+
+	before := calls.Load()
+	sampler.SampleNow("UCchannel")
+	sampler.Stop()
+	if after := calls.Load(); after != before {
+		t.Errorf("SampleNow after Stop made %d upstream calls", after-before)
+	}
+}
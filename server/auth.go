@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"yt_search_server/store"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeIndex = "index"
+	ScopeAdmin = "admin"
+)
+
+var scopes = []string{ScopeRead, ScopeIndex, ScopeAdmin}
+
+// ClientKeyHeader is an alternative to an Authorization: Bearer header.
+const ClientKeyHeader = "X-API-Key"
+
+const clientKeyPrefix = "yts_"
+
+// publicRoutes are served without a key even when keys are required, so
+// probes and scrapers need no credentials.
+var publicRoutes = map[string]bool{
+	"/":             true,
+	"/healthz":      true,
+	"/readyz":       true,
+	"/metrics":      true,
+	"/openapi.json": true,
+	"/docs":         true,
+}
+
+// routeScopes lists the routes that need more than the read scope.
+var routeScopes = map[string]string{
+	"PUT /v2/tracked/{id}":    ScopeIndex,
+	"DELETE /v2/tracked/{id}": ScopeIndex,
+	"GET /v2/keys":            ScopeAdmin,
+	"POST /v2/keys":           ScopeAdmin,
+	"DELETE /v2/keys/{id}":    ScopeAdmin,
+}
+
+type clientKeyContextKey struct{}
+
+func withClientKey(ctx context.Context, key store.ClientKey) context.Context {
+	return context.WithValue(ctx, clientKeyContextKey{}, key)
+}
+
+func clientKeyFrom(ctx context.Context) (store.ClientKey, bool) {
+	key, ok := ctx.Value(clientKeyContextKey{}).(store.ClientKey)
+	return key, ok
+}
+
+func hashClientKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// presentedKey returns the key in the Authorization or X-API-Key header.
+func presentedKey(req *http.Request) string {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return req.Header.Get(ClientKeyHeader)
+}
+
+func hasScope(key store.ClientKey, scope string) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func routeTemplate(req *http.Request) string {
+	if current := mux.CurrentRoute(req); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return unmatchedRoute
+}
+
+func writeUnauthorized(w http.ResponseWriter, req *http.Request, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="yt_search_server"`)
+	writeProblem(w, req, http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+func (server *Server) authenticate(token string, adminToken string) (store.ClientKey, bool) {
+	if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+		return store.ClientKey{Id: "admin", Name: "admin token", Scopes: []string{ScopeAdmin}}, true
+	}
+
+	key, ok := server.store.ClientKeyByHash(hashClientKey(token))
+	if !ok || !key.Active(time.Now()) {
+		return store.ClientKey{}, false
+	}
+	return key, true
+}
+
+// Authenticate identifies the client by its key and checks that the key
+// grants the scope the route needs. When keys are not required, routes that
+// need only the read scope are open to anyone, and a key there only sets the
+// client's rate limit; tracking channels and managing keys still need a key.
+// adminToken, when set, is accepted as a key with the admin scope, so the
+// first keys can be created.
+func (server *Server) Authenticate(required bool, adminToken string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token := presentedKey(req)
+			key, authenticated := store.ClientKey{}, false
+			if token != "" {
+				key, authenticated = server.authenticate(token, adminToken)
+			}
+			if authenticated {
+				req = req.WithContext(withClientKey(req.Context(), key))
+			}
+
+			route := routeTemplate(req)
+			scope, ok := routeScopes[req.Method+" "+route]
+			if !ok {
+				scope = ScopeRead
+			}
+			if publicRoutes[route] || (!required && scope == ScopeRead) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			if token == "" {
+				writeUnauthorized(w, req, "An API key is required.")
+				return
+			}
+			if !authenticated {
+				writeUnauthorized(w, req, "The API key is invalid, expired or revoked.")
+				return
+			}
+
+			if !hasScope(key, scope) {
+				writeProblem(
+					w, req, http.StatusForbidden, CodeForbidden,
+					fmt.Sprintf("The API key lacks the %s scope.", scope),
+				)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"yt_search_server/store"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAuthenticate(t *testing.T) {
+	const adminToken = "admin-token-admin-token-admin-token"
+
+	keys, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.AddClientKey(store.ClientKey{Id: "reader", Hash: hashClientKey("yts_reader"), Scopes: []string{ScopeRead}})
+	keys.AddClientKey(store.ClientKey{Id: "indexer", Hash: hashClientKey("yts_indexer"), Scopes: []string{ScopeIndex}})
+	server := NewServer(nil, keys, nil)
+
+	reached := func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusTeapot) }
+	newRouter := func(required bool) *mux.Router {
+		router := mux.NewRouter()
+		router.HandleFunc("/healthz", reached).Methods("GET")
+		router.HandleFunc("/v2/channels/{id}", reached).Methods("GET")
+		router.HandleFunc("/v2/keys", reached).Methods("GET", "POST")
+		router.HandleFunc("/v2/keys/{id}", reached).Methods("DELETE")
+		router.HandleFunc("/v2/tracked/{id}", reached).Methods("PUT", "DELETE")
+		router.Use(server.Authenticate(required, adminToken))
+		return router
+	}
+
+	tests := []struct {
+		name     string
+		required bool
+		method   string
+		path     string
+		key      string
+		want     int
+	}{
+		{name: "anonymous read", method: "GET", path: "/v2/channels/UC1", want: http.StatusTeapot},
+		{name: "anonymous key creation", method: "POST", path: "/v2/keys", want: http.StatusUnauthorized},
+		{name: "anonymous key listing", method: "GET", path: "/v2/keys", want: http.StatusUnauthorized},
+		{name: "anonymous key revocation", method: "DELETE", path: "/v2/keys/k1", want: http.StatusUnauthorized},
+		{name: "anonymous tracking", method: "PUT", path: "/v2/tracked/UC1", want: http.StatusUnauthorized},
+		{name: "invalid key on admin route", method: "POST", path: "/v2/keys", key: "yts_bogus", want: http.StatusUnauthorized},
+		{name: "read key on admin route", method: "POST", path: "/v2/keys", key: "yts_reader", want: http.StatusForbidden},
+		{name: "admin token creates keys", method: "POST", path: "/v2/keys", key: adminToken, want: http.StatusTeapot},
+		{name: "index key tracks", method: "DELETE", path: "/v2/tracked/UC1", key: "yts_indexer", want: http.StatusTeapot},
+
+		{name: "required: no key", required: true, method: "GET", path: "/v2/channels/UC1", want: http.StatusUnauthorized},
+		{name: "required: public route", required: true, method: "GET", path: "/healthz", want: http.StatusTeapot},
+		{name: "required: read key", required: true, method: "GET", path: "/v2/channels/UC1", key: "yts_reader", want: http.StatusTeapot},
+		{name: "required: read key on index route", required: true, method: "PUT", path: "/v2/tracked/UC1", key: "yts_reader", want: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader("{}"))
+			if test.key != "" {
+				req.Header.Set("Authorization", "Bearer "+test.key)
+			}
+			w := httptest.NewRecorder()
+			newRouter(test.required).ServeHTTP(w, req)
+
+			if w.Code != test.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, test.want, w.Body)
+			}
+		})
+	}
+}
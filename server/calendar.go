@@ -0,0 +1,202 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+const icalProductId = "-//yt-chrono-search//Upload calendar//EN"
+
+// Events without a known duration, such as upcoming livestreams and predicted
+// slots, are given a nominal length so calendars can display them.
+const defaultEventDuration = 15 * time.Minute
+
+var icalEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+type icalWriter struct {
+	builder strings.Builder
+}
+
+// line writes one content line, folded at 75 octets as RFC 5545 requires
+// without splitting a UTF-8 sequence.
+func (writer *icalWriter) line(name string, value string) {
+	content := name + ":" + value
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		writer.builder.WriteString(content[:cut])
+		writer.builder.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74
+	}
+	writer.builder.WriteString(content)
+	writer.builder.WriteString("\r\n")
+}
+
+func (writer *icalWriter) text(name string, value string) {
+	writer.line(name, icalEscaper.Replace(value))
+}
+
+func (writer *icalWriter) time(name string, value time.Time) {
+	writer.line(name, value.UTC().Format(icalTimeFormat))
+}
+
+func parsePredictParam(req *http.Request) (int, error) {
+	weeks, err := parseIntParam(req, "predict", 0)
+	if err != nil {
+		return 0, err
+	}
+	if weeks < 0 || weeks > youtube.MaxPredictionWeeks {
+		return 0, fmt.Errorf(
+			"Query parameter 'predict' must be a number of weeks between 0 and %d.",
+			youtube.MaxPredictionWeeks,
+		)
+	}
+	return weeks, nil
+}
+
+func (server *Server) GetChannelCalendarV2(w http.ResponseWriter, req *http.Request) {
+	location, err := parseTimezoneParam(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	from, err := parseTimeParam(req, "from", location)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	to, err := parseTimeParam(req, "to", location)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		writeBadRequest(w, req, "Query parameter 'from' must be before 'to'.")
+		return
+	}
+
+	weeks, err := parsePredictParam(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	options, err := parseChannelVideosOptions(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, err := server.youtubeFor(req).GetChannel(channelId)
+	if err != nil {
+		writeError(w, req, err, "fetching channel")
+		return
+	}
+
+	// The uploads and the predictions are both drawn from one timeline.
+	videos, err := server.youtubeFor(req).GetTimeline(channelId, options)
+	if err != nil {
+		writeError(w, req, err, "fetching videos")
+		return
+	}
+
+	uploads, err := server.youtubeFor(req).GetCalendarUploads(videos, from, to)
+	if err != nil {
+		writeError(w, req, err, "fetching videos")
+		return
+	}
+
+	now := time.Now()
+
+	predictions, err := youtube.PredictUploads(videos, location, now, weeks)
+	if err != nil {
+		writeError(w, req, err, "predicting uploads")
+		return
+	}
+
+	calendar := &icalWriter{}
+	calendar.line("BEGIN", "VCALENDAR")
+	calendar.line("VERSION", "2.0")
+	calendar.line("PRODID", icalProductId)
+	calendar.line("CALSCALE", "GREGORIAN")
+	calendar.line("METHOD", "PUBLISH")
+	calendar.text("X-WR-CALNAME", fmt.Sprintf("%s uploads", channel.ChannelTitle))
+	calendar.text("X-WR-TIMEZONE", location.String())
+
+	for _, upload := range uploads {
+		duration := time.Duration(upload.Duration) * time.Second
+		if duration <= 0 {
+			duration = defaultEventDuration
+		}
+
+		summary := upload.Title
+		if upload.Livestream {
+			summary = "[Live] " + summary
+		}
+
+		calendar.line("BEGIN", "VEVENT")
+		calendar.text("UID", fmt.Sprintf("%s@yt-chrono-search", upload.VideoId))
+		calendar.time("DTSTAMP", now)
+		calendar.time("DTSTART", upload.PublishedAt)
+		calendar.time("DTEND", upload.PublishedAt.Add(duration))
+		calendar.text("SUMMARY", summary)
+		calendar.line("URL", watchUrl(upload.VideoId))
+		calendar.text("DESCRIPTION", watchUrl(upload.VideoId)+"\n\n"+upload.Description)
+		calendar.line("TRANSP", "TRANSPARENT")
+		calendar.line("END", "VEVENT")
+	}
+
+	for _, prediction := range predictions {
+		calendar.line("BEGIN", "VEVENT")
+		calendar.text("UID", fmt.Sprintf(
+			"predicted-%s-%s@yt-chrono-search",
+			channelId,
+			prediction.Start.UTC().Format(icalTimeFormat),
+		))
+		calendar.time("DTSTAMP", now)
+		calendar.time("DTSTART", prediction.Start)
+		calendar.time("DTEND", prediction.Start.Add(defaultEventDuration))
+		calendar.text("SUMMARY", fmt.Sprintf("Predicted upload: %s", channel.ChannelTitle))
+		calendar.text("DESCRIPTION", fmt.Sprintf(
+			"%.0f%% of recent uploads were published on %ss around %02d:00 (%s).",
+			prediction.Share*100,
+			prediction.Slot.Weekday,
+			prediction.Slot.Hour,
+			location,
+		))
+		calendar.line("URL", "https://www.youtube.com/channel/"+channel.ChannelId)
+		calendar.line("STATUS", "TENTATIVE")
+		calendar.line("TRANSP", "TRANSPARENT")
+		calendar.line("END", "VEVENT")
+	}
+
+	calendar.line("END", "VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", contentDisposition("inline", channelId+".ics"))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(calendar.builder.String()))
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"yt_search_server/logging"
+)
+
+const (
+	// crawlWriteTimeout replaces the server's write timeout for routes that
+	// crawl a whole channel timeline, which takes minutes for the largest
+	// channels before anything is written.
+	crawlWriteTimeout = 30 * time.Minute
+	// streamWriteTimeout is the time a streaming response is given after each
+	// batch it writes, so it runs as long as it keeps making progress.
+	streamWriteTimeout = 5 * time.Minute
+)
+
+// extendWriteDeadline lets the response take until timeout from now, past
+// the server's write timeout. Writers without a deadline, such as test
+// recorders, are left as they are.
+func extendWriteDeadline(w http.ResponseWriter, req *http.Request, timeout time.Duration) {
+	err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logging.FromContext(req.Context()).Warn("Cannot extend write deadline", "error", err)
+	}
+}
+
+// crawling gives handler crawlWriteTimeout to answer.
+func crawling(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		extendWriteDeadline(w, req, crawlWriteTimeout)
+		handler(w, req)
+	}
+}
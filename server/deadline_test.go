@@ -0,0 +1,51 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCrawlingOutlastsWriteTimeout(t *testing.T) {
+	const writeTimeout = 100 * time.Millisecond
+
+	slow := func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(3 * writeTimeout)
+		w.Write([]byte("done"))
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    bool
+	}{
+		{name: "plain", handler: slow, want: false},
+		{name: "crawling", handler: crawling(slow), want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewServer(nil, nil, nil)
+			// The deadline is set through the middleware's response writers.
+			handler := server.LogRequests(server.RecoverPanics(test.handler))
+
+			ts := httptest.NewUnstartedServer(handler)
+			ts.Config.WriteTimeout = writeTimeout
+			ts.Start()
+			defer ts.Close()
+
+			res, err := http.Get(ts.URL)
+			got := false
+			if err == nil {
+				body, readErr := io.ReadAll(res.Body)
+				res.Body.Close()
+				got = readErr == nil && string(body) == "done"
+			}
+			if got != test.want {
+				t.Errorf("response complete = %v, want %v (error %v)", got, test.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"yt_search_server/logging"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+// exportFlushRows is the number of rows written between pushes to the client.
+const exportFlushRows = 50
+
+type exportRow struct {
+	position int
+	video    youtube.PlaylistVideo
+	details  *youtube.VideoDetails
+}
+
+type exportColumn struct {
+	name  string
+	value func(row exportRow) interface{}
+}
+
+func optionalCount(value string) interface{} {
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return count
+}
+
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+var exportColumns = []exportColumn{
+	{"position", func(row exportRow) interface{} { return row.position }},
+	{"videoId", func(row exportRow) interface{} { return row.video.VideoId }},
+	{"title", func(row exportRow) interface{} { return row.details.Title }},
+	{"url", func(row exportRow) interface{} {
+		return "https://www.youtube.com/watch?v=" + row.video.VideoId
+	}},
+	{"publishedAt", func(row exportRow) interface{} { return row.details.PublishedAt }},
+	{"videoPublishedAt", func(row exportRow) interface{} { return row.video.PublishedAt }},
+	{"duration", func(row exportRow) interface{} { return row.details.Duration }},
+	{"definition", func(row exportRow) interface{} { return row.details.Definition }},
+	{"caption", func(row exportRow) interface{} { return row.details.Caption }},
+	{"licensedContent", func(row exportRow) interface{} { return row.details.LicensedContent }},
+	{"livestream", func(row exportRow) interface{} { return row.details.Livestream }},
+	{"actualStartTime", func(row exportRow) interface{} { return optionalString(row.details.ActualStartTime) }},
+	{"recordingDate", func(row exportRow) interface{} { return optionalString(row.details.RecordingDate) }},
+	{"viewCount", func(row exportRow) interface{} { return optionalCount(row.details.ViewCount) }},
+	{"likeCount", func(row exportRow) interface{} { return optionalCount(row.details.LikeCount) }},
+	{"commentCount", func(row exportRow) interface{} { return optionalCount(row.details.CommentCount) }},
+	{"thumbnail", func(row exportRow) interface{} { return row.details.Thumbnail }},
+	{"tags", func(row exportRow) interface{} { return row.details.Tags }},
+	{"description", func(row exportRow) interface{} { return row.details.Description }},
+}
+
+var defaultExportColumns = []string{
+	"position", "videoId", "title", "url", "publishedAt", "duration", "viewCount",
+}
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"jsonl":  "application/jsonl; charset=utf-8",
+	"ndjson": "application/x-ndjson; charset=utf-8",
+}
+
+func parseExportColumns(req *http.Request) ([]exportColumn, error) {
+	names := defaultExportColumns
+	if value := req.URL.Query().Get("columns"); value != "" {
+		names = strings.Split(value, ",")
+	}
+
+	columns := make([]exportColumn, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, column := range exportColumns {
+			if column.name == name {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown column '%s'.", name)
+		}
+	}
+
+	return columns, nil
+}
+
+type exportWriter interface {
+	writeHeader(columns []exportColumn) error
+	writeRow(columns []exportColumn, row exportRow) error
+	flush() error
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (writer *csvExportWriter) writeHeader(columns []exportColumn) error {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	return writer.writer.Write(header)
+}
+
+func (writer *csvExportWriter) writeRow(columns []exportColumn, row exportRow) error {
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		switch value := column.value(row).(type) {
+		case nil:
+			record[i] = ""
+		case []string:
+			record[i] = strings.Join(value, "|")
+		default:
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return writer.writer.Write(record)
+}
+
+func (writer *csvExportWriter) flush() error {
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+type jsonLinesExportWriter struct {
+	writer http.ResponseWriter
+}
+
+func (writer *jsonLinesExportWriter) writeHeader(columns []exportColumn) error {
+	return nil
+}
+
+// Objects are assembled by hand so keys keep the requested column order.
+func (writer *jsonLinesExportWriter) writeRow(columns []exportColumn, row exportRow) error {
+	var line strings.Builder
+	line.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		key, _ := json.Marshal(column.name)
+		value, err := json.Marshal(column.value(row))
+		if err != nil {
+			return err
+		}
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+	line.WriteString("}\n")
+
+	_, err := writer.writer.Write([]byte(line.String()))
+	return err
+}
+
+func (writer *jsonLinesExportWriter) flush() error {
+	return nil
+}
+
+func (server *Server) ExportChannelV2(w http.ResponseWriter, req *http.Request) {
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		writeBadRequest(w, req, "Query parameter 'format' must be one of csv, jsonl, ndjson.")
+		return
+	}
+
+	columns, err := parseExportColumns(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	options, err := parseChannelVideosOptions(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	videos, err := server.youtubeFor(req).GetTimeline(channelId, options)
+	if err != nil {
+		writeError(w, req, err, "fetching videos")
+		return
+	}
+
+	videoIds := make([]string, len(videos))
+	for i, video := range videos {
+		videoIds[i] = video.VideoId
+	}
+
+	// Nothing is written until the first batch of details arrives, so an
+	// early upstream failure can still be reported with a proper status.
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", contentDisposition("attachment", channelId+"."+format))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	var writer exportWriter
+	if format == "csv" {
+		writer = &csvExportWriter{writer: csv.NewWriter(w)}
+	} else {
+		writer = &jsonLinesExportWriter{writer: w}
+	}
+
+	flusher, _ := w.(http.Flusher)
+	started := false
+	written := 0
+
+	err = server.youtubeFor(req).ForEachVideoDetails(
+		videoIds,
+		func(position int, details *youtube.VideoDetails) error {
+			if !started {
+				started = true
+				w.WriteHeader(http.StatusOK)
+				err := writer.writeHeader(columns)
+				if err != nil {
+					return err
+				}
+			}
+
+			err := writer.writeRow(columns, exportRow{
+				position: position,
+				video:    videos[position],
+				details:  details,
+			})
+			if err != nil {
+				return err
+			}
+			written++
+
+			// Push each completed batch to the client. Videos without details
+			// are skipped, so batches are counted in rows written.
+			if written%exportFlushRows == 0 {
+				err = writer.flush()
+				if err != nil {
+					return err
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+				extendWriteDeadline(w, req, streamWriteTimeout)
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil && !started {
+		writeError(w, req, err, "fetching video details")
+		return
+	}
+
+	if err != nil {
+		logging.FromContext(req.Context()).Error(
+			"Error while exporting channel", "channelId", channelId, "error", err,
+		)
+		// Abort the connection so the client sees a truncated transfer
+		// instead of a complete-looking export.
+		panic(http.ErrAbortHandler)
+	}
+
+	if !started {
+		w.WriteHeader(http.StatusOK)
+		writer.writeHeader(columns)
+	}
+
+	writer.flush()
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// flushRecorder records how many lines of the body had been written at each
+// flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []int
+}
+
+func (recorder *flushRecorder) Flush() {
+	recorder.flushes = append(recorder.flushes, strings.Count(recorder.Body.String(), "\n"))
+	recorder.ResponseRecorder.Flush()
+}
+
+func TestExportFlushesEveryBatchOfRows(t *testing.T) {
+	// Hidden videos are skipped, including the ones at the positions that
+	// would end a batch if batches were counted in positions.
+	videos := fakeVideos(130)
+	for _, i := range []int{10, 49, 99} {
+		videos[i].hidden = true
+	}
+
+	server := NewServer(useChannel(t, "Channel", videos), nil, nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/channels/{id}/export", server.ExportChannelV2)
+
+	req := httptest.NewRequest("GET", "/v2/channels/UCchannel/export?format=csv", nil)
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	// A header line, then 50 rows per batch.
+	want := []int{1 + exportFlushRows, 1 + 2*exportFlushRows}
+	if len(w.flushes) != len(want) || w.flushes[0] != want[0] || w.flushes[1] != want[1] {
+		t.Errorf("flushed after %v lines, want %v", w.flushes, want)
+	}
+	if lines := strings.Count(w.Body.String(), "\n"); lines != 1+127 {
+		t.Errorf("export has %d lines, want a header and 127 rows", lines)
+	}
+	if header := w.Header().Get("Content-Disposition"); header != "attachment; filename=UCchannel.csv" {
+		t.Errorf("Content-Disposition = %q", header)
+	}
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+	"yt_search_server/logging"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultFeedLimit = 50
+
+const maxFeedLimit = 200
+
+const mediaNamespace = "http://search.yahoo.com/mrss/"
+
+type mediaThumbnail struct {
+	XMLName xml.Name `xml:"media:thumbnail"`
+	Url     string   `xml:"url,attr"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	Guid        rssGuid         `xml:"guid"`
+	PubDate     string          `xml:"pubDate"`
+	Description string          `xml:"description"`
+	Thumbnail   *mediaThumbnail `xml:"media:thumbnail,omitempty"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName    xml.Name   `xml:"rss"`
+	Version    string     `xml:"version,attr"`
+	MediaSpace string     `xml:"xmlns:media,attr"`
+	Channel    rssChannel `xml:"channel"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Id        string          `xml:"id"`
+	Title     string          `xml:"title"`
+	Link      atomLink        `xml:"link"`
+	Published string          `xml:"published"`
+	Updated   string          `xml:"updated"`
+	Summary   atomText        `xml:"summary"`
+	Thumbnail *mediaThumbnail `xml:"media:thumbnail,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName    xml.Name     `xml:"feed"`
+	Namespace  string       `xml:"xmlns,attr"`
+	MediaSpace string       `xml:"xmlns:media,attr"`
+	Id         string       `xml:"id"`
+	Title      string       `xml:"title"`
+	Updated    string       `xml:"updated"`
+	Links      []atomLink   `xml:"link"`
+	Entries    []*atomEntry `xml:"entry"`
+}
+
+func watchUrl(videoId string) string {
+	return "https://www.youtube.com/watch?v=" + videoId
+}
+
+func rewatchSummary(item *youtube.RewatchItem) string {
+	summary := fmt.Sprintf(
+		"<p>Upload #%d, originally published %s.</p>",
+		item.Episode,
+		html.EscapeString(item.Metadata.PublishedAt),
+	)
+	if item.Metadata.VideoThumbnail != "" {
+		summary = fmt.Sprintf(
+			"<p><a href=\"%s\"><img src=\"%s\" alt=\"\"></a></p>",
+			html.EscapeString(watchUrl(item.Metadata.VideoId)),
+			html.EscapeString(item.Metadata.VideoThumbnail),
+		) + summary
+	}
+	return summary
+}
+
+func thumbnailElement(url string) *mediaThumbnail {
+	if url == "" {
+		return nil
+	}
+	return &mediaThumbnail{Url: url}
+}
+
+func (server *Server) GetRewatchFeedV2(w http.ResponseWriter, req *http.Request) {
+	format := req.URL.Query().Get("format")
+	if format == "" {
+		format = "rss"
+	}
+	if format != "rss" && format != "atom" {
+		writeBadRequest(w, req, "Query parameter 'format' must be rss or atom.")
+		return
+	}
+
+	start, err := parseTimeParam(req, "start", time.UTC)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+	if start.IsZero() {
+		writeBadRequest(w, req, "Query parameter 'start' missing.")
+		return
+	}
+
+	interval := 24 * time.Hour
+	if value := req.URL.Query().Get("interval"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval < time.Minute {
+			writeBadRequest(w, req, "Query parameter 'interval' must be a duration of at least 1m, such as 24h.")
+			return
+		}
+	}
+
+	limit, err := parseLimitParam(req, defaultFeedLimit, maxFeedLimit)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, err := server.youtubeFor(req).GetChannel(channelId)
+	if err != nil {
+		writeError(w, req, err, "fetching channel")
+		return
+	}
+
+	now := time.Now().UTC()
+
+	items, err := server.youtubeFor(req).GetRewatchSchedule(
+		channelId, req.URL.Query().Get("from"), start, interval, limit, now,
+	)
+	if err != nil {
+		writeError(w, req, err, "building feed")
+		return
+	}
+
+	title := fmt.Sprintf("%s: rewatch from the beginning", channel.ChannelTitle)
+	channelUrl := "https://www.youtube.com/channel/" + channel.ChannelId
+	description := fmt.Sprintf(
+		"The uploads of %s, oldest first, one every %s starting %s.",
+		channel.ChannelTitle,
+		interval,
+		start.Format(time.RFC3339),
+	)
+
+	updated := start
+	if len(items) > 0 {
+		updated = items[0].ReleasedAt
+	}
+
+	var feed interface{}
+	if format == "rss" {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+
+		rssItems := make([]*rssItem, len(items))
+		for i, item := range items {
+			rssItems[i] = &rssItem{
+				Title:       item.Metadata.VideoTitle,
+				Link:        watchUrl(item.Metadata.VideoId),
+				Guid:        rssGuid{Value: "yt:video:" + item.Metadata.VideoId},
+				PubDate:     item.ReleasedAt.Format(time.RFC1123Z),
+				Description: rewatchSummary(item),
+				Thumbnail:   thumbnailElement(item.Metadata.VideoThumbnail),
+			}
+		}
+
+		feed = rssFeed{
+			Version:    "2.0",
+			MediaSpace: mediaNamespace,
+			Channel: rssChannel{
+				Title:         title,
+				Link:          channelUrl,
+				Description:   description,
+				LastBuildDate: updated.Format(time.RFC1123Z),
+				Items:         rssItems,
+			},
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+
+		entries := make([]*atomEntry, len(items))
+		for i, item := range items {
+			entries[i] = &atomEntry{
+				Id:        "yt:video:" + item.Metadata.VideoId,
+				Title:     item.Metadata.VideoTitle,
+				Link:      atomLink{Href: watchUrl(item.Metadata.VideoId)},
+				Published: item.ReleasedAt.Format(time.RFC3339),
+				Updated:   item.ReleasedAt.Format(time.RFC3339),
+				Summary:   atomText{Type: "html", Value: rewatchSummary(item)},
+				Thumbnail: thumbnailElement(item.Metadata.VideoThumbnail),
+			}
+		}
+
+		feed = atomFeed{
+			Namespace:  "http://www.w3.org/2005/Atom",
+			MediaSpace: mediaNamespace,
+			Id:         "urn:yt-rewatch:" + channel.ChannelId + ":" + start.Format(time.RFC3339),
+			Title:      title,
+			Updated:    updated.Format(time.RFC3339),
+			Links: []atomLink{
+				{Href: channelUrl},
+				{Href: requestUrl(req), Rel: "self"},
+			},
+			Entries: entries,
+		}
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		logging.FromContext(req.Context()).Error("Error forming XML", "error", err)
+		writeProblem(
+			w, req, http.StatusInternalServerError, CodeInternalError,
+			"The server failed to encode the feed.",
+		)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
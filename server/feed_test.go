@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const escapedTitle = `A & B <C> "D" 'E'`
+
+// getFeed requests the rewatch feed of a channel of eight uploads, the third
+// of which has a title that needs escaping, started so that five have been
+// released.
+func getFeed(t *testing.T, query string) *httptest.ResponseRecorder {
+	videos := fakeVideos(8)
+	videos[2].title = escapedTitle
+
+	server := NewServer(useChannel(t, "Tom & Jerry", videos), nil, nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/channels/{id}/feed", server.GetRewatchFeedV2)
+
+	start := time.Now().UTC().Add(-4*24*time.Hour - time.Hour).Format(time.RFC3339)
+	req := httptest.NewRequest("GET", "/v2/channels/UCchannel/feed?start="+start+query, nil)
+	req.Host = "feeds.example.com"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	return w
+}
+
+// checkWellFormed reads the whole document with a strict decoder.
+func checkWellFormed(t *testing.T, body string) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("feed is not well-formed: %s", err)
+		}
+	}
+}
+
+func TestRssFeed(t *testing.T) {
+	w := getFeed(t, "")
+	checkWellFormed(t, w.Body.String())
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/rss+xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+
+	var feed struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title       string `xml:"title"`
+				Link        string `xml:"link"`
+				Guid        string `xml:"guid"`
+				PubDate     string `xml:"pubDate"`
+				Description string `xml:"description"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("decoding feed: %s", err)
+	}
+
+	if feed.Version != "2.0" {
+		t.Errorf("version = %q", feed.Version)
+	}
+	if feed.Channel.Title != "Tom & Jerry: rewatch from the beginning" {
+		t.Errorf("channel title = %q", feed.Channel.Title)
+	}
+
+	want := []string{"video0004", "video0003", "video0002", "video0001", "video0000"}
+	if len(feed.Channel.Items) != len(want) {
+		t.Fatalf("feed has %d items, want %d", len(feed.Channel.Items), len(want))
+	}
+
+	var previous time.Time
+	for i, item := range feed.Channel.Items {
+		if item.Guid != "yt:video:"+want[i] || item.Link != watchUrl(want[i]) {
+			t.Errorf("item %d is %s at %s, want %s", i, item.Guid, item.Link, want[i])
+		}
+		released, err := time.Parse(time.RFC1123Z, item.PubDate)
+		if err != nil {
+			t.Errorf("item %d: pubDate %q: %s", i, item.PubDate, err)
+		}
+		if i > 0 && !released.Before(previous) {
+			t.Errorf("item %d released %s, not before the item above it", i, released)
+		}
+		previous = released
+	}
+
+	if title := feed.Channel.Items[2].Title; title != escapedTitle {
+		t.Errorf("title = %q, want %q", title, escapedTitle)
+	}
+	if !strings.Contains(feed.Channel.Items[2].Description, `<img src="https://example.com/video0002.jpg"`) {
+		t.Errorf("description = %q", feed.Channel.Items[2].Description)
+	}
+}
+
+func TestAtomFeed(t *testing.T) {
+	w := getFeed(t, "&format=atom&limit=3")
+	checkWellFormed(t, w.Body.String())
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/atom+xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+
+	var feed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Title   string   `xml:"title"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Entries []struct {
+			Id      string `xml:"id"`
+			Title   string `xml:"title"`
+			Updated string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("decoding feed: %s", err)
+	}
+
+	self := ""
+	for _, link := range feed.Links {
+		if link.Rel == "self" {
+			self = link.Href
+		}
+	}
+	if !strings.HasPrefix(self, "http://feeds.example.com/v2/channels/UCchannel/feed?") ||
+		!strings.HasSuffix(self, "&format=atom&limit=3") {
+		t.Errorf("self link = %q, want the absolute URL of the request", self)
+	}
+
+	want := []string{"video0004", "video0003", "video0002"}
+	if len(feed.Entries) != len(want) {
+		t.Fatalf("feed has %d entries, want %d", len(feed.Entries), len(want))
+	}
+	for i, entry := range feed.Entries {
+		if entry.Id != "yt:video:"+want[i] {
+			t.Errorf("entry %d is %s, want %s", i, entry.Id, want[i])
+		}
+	}
+	if feed.Entries[2].Title != escapedTitle {
+		t.Errorf("title = %q, want %q", feed.Entries[2].Title, escapedTitle)
+	}
+}
+
+func TestRequestUrl(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v2/channels/UCchannel/feed?start=2024-01-01&format=atom", nil)
+	req.Host = "feeds.example.com:8080"
+	if got, want := requestUrl(req), "http://feeds.example.com:8080/v2/channels/UCchannel/feed?start=2024-01-01&format=atom"; got != want {
+		t.Errorf("requestUrl = %q, want %q", got, want)
+	}
+
+	req = httptest.NewRequest("GET", "https://feeds.example.com/v2/channels/UCchannel/feed", nil)
+	if got, want := requestUrl(req), "https://feeds.example.com/v2/channels/UCchannel/feed"; got != want {
+		t.Errorf("requestUrl = %q, want %q", got, want)
+	}
+}
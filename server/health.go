@@ -0,0 +1,96 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type KeyCheck struct {
+	Key           int       `json:"key"`
+	Ok            bool      `json:"ok"`
+	QuotaExceeded bool      `json:"quotaExceeded"`
+	Reason        string    `json:"reason,omitempty"`
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+type DependencyCheck struct {
+	Ok     bool       `json:"ok"`
+	Detail string     `json:"detail,omitempty"`
+	Keys   []KeyCheck `json:"keys,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Ready  bool                       `json:"ready"`
+	Checks map[string]DependencyCheck `json:"checks"`
+}
+
+// GetHealthz only confirms that the process is serving requests.
+func (server *Server) GetHealthz(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJson(w, req, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// GetReadyz checks that the API keys work, that at least one has quota left
+// and that the store can be written, answering 503 unless all hold.
+func (server *Server) GetReadyz(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	statuses := server.youtubeFor(req).ProbeKeys()
+
+	keys := make([]KeyCheck, 0, len(statuses))
+	working, exhausted := 0, 0
+	for _, status := range statuses {
+		keys = append(keys, KeyCheck{
+			Key:           status.Key,
+			Ok:            status.Ok,
+			QuotaExceeded: status.QuotaExceeded,
+			Reason:        status.Reason,
+			CheckedAt:     status.CheckedAt,
+		})
+		if status.Ok {
+			working++
+		}
+		if status.QuotaExceeded {
+			exhausted++
+		}
+	}
+
+	checks := make(map[string]DependencyCheck)
+
+	youtubeCheck := DependencyCheck{Ok: working > 0, Keys: keys}
+	switch {
+	case len(statuses) == 0:
+		youtubeCheck.Detail = "no API keys configured"
+	case working == 0 && exhausted < len(statuses):
+		youtubeCheck.Detail = "no API key could call the Data API"
+	default:
+		youtubeCheck.Detail = fmt.Sprintf("%d of %d API keys working", working, len(statuses))
+	}
+	checks["youtube"] = youtubeCheck
+
+	quotaCheck := DependencyCheck{Ok: len(statuses) > exhausted}
+	quotaCheck.Detail = fmt.Sprintf("%d of %d API keys out of quota", exhausted, len(statuses))
+	checks["quota"] = quotaCheck
+
+	storeCheck := DependencyCheck{Ok: true}
+	err := server.store.CheckWritable()
+	if err != nil {
+		storeCheck = DependencyCheck{Ok: false, Detail: err.Error()}
+	}
+	checks["store"] = storeCheck
+
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.Ok
+	}
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJson(w, req, status, ReadinessResponse{Ready: ready, Checks: checks})
+}
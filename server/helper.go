@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"yt_search_server/logging"
+	"yt_search_server/youtube"
+)
+
+func setJsonContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+}
+
+// writeJson answers with v encoded as JSON. Nothing has been written when v
+// fails to encode, so the failure can still be answered with a problem.
+func writeJson(w http.ResponseWriter, req *http.Request, status int, v interface{}) {
+	jsonResp, err := json.Marshal(v)
+	if err != nil {
+		logging.FromContext(req.Context()).Error("Error forming JSON", "error", err)
+		writeProblem(
+			w, req, http.StatusInternalServerError, CodeInternalError,
+			"The server failed to encode the response.",
+		)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(jsonResp)
+}
+
+func writeMessage(w http.ResponseWriter, req *http.Request, status int, message string) {
+	resp := make(map[string]string)
+	resp["message"] = message
+	writeJson(w, req, status, resp)
+}
+
+// contentDisposition names the file a response is saved as. The name holds
+// the channel ID from the request, so it is escaped rather than pasted in.
+func contentDisposition(disposition string, filename string) string {
+	return mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+}
+
+// requestUrl is the absolute URL a request was made to, as seen by the client
+// through its Host header.
+func requestUrl(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return (&url.URL{Scheme: scheme, Host: req.Host}).String() + req.URL.RequestURI()
+}
+
+func parseSecondsParam(req *http.Request, name string) (int, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf(
+			"Query parameter '%s' must be a non-negative number of seconds.", name,
+		)
+	}
+
+	return seconds, nil
+}
+
+func parseChannelVideosOptions(
+	req *http.Request,
+) (youtube.ChannelVideosOptions, error) {
+	options := youtube.ChannelVideosOptions{}
+
+	contentType, err := youtube.ParseContentType(req.URL.Query().Get("type"))
+	if err != nil {
+		return options, fmt.Errorf("Query parameter 'type': %s.", err)
+	}
+	options.ContentType = contentType
+
+	sortKey, err := youtube.ParseSortKey(req.URL.Query().Get("sortKey"))
+	if err != nil {
+		return options, fmt.Errorf("Query parameter 'sortKey': %s.", err)
+	}
+	options.SortKey = sortKey
+
+	descending, err := youtube.ParseSortOrder(req.URL.Query().Get("order"))
+	if err != nil {
+		return options, fmt.Errorf("Query parameter 'order': %s.", err)
+	}
+	options.Descending = descending
+
+	minDuration, err := parseSecondsParam(req, "minDuration")
+	if err != nil {
+		return options, err
+	}
+
+	maxDuration, err := parseSecondsParam(req, "maxDuration")
+	if err != nil {
+		return options, err
+	}
+
+	if maxDuration > 0 && minDuration > maxDuration {
+		return options, fmt.Errorf(
+			"Query parameter 'minDuration' must not exceed 'maxDuration'.",
+		)
+	}
+
+	options.MinDuration = minDuration
+	options.MaxDuration = maxDuration
+
+	return options, nil
+}
+
+func parseLimitParam(req *http.Request, defaultLimit int, maxLimit int) (int, error) {
+	value := req.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf(
+			"Query parameter 'limit' must be a number between 1 and %d.", maxLimit,
+		)
+	}
+
+	return limit, nil
+}
+
+// parseTimeParam reads a date (YYYY-MM-DD, midnight in location) or an RFC 3339
+// time. A missing parameter yields the zero time.
+func parseTimeParam(
+	req *http.Request, name string, location *time.Location,
+) (time.Time, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"Query parameter '%s' must be a date (YYYY-MM-DD) or an RFC 3339 time.",
+			name,
+		)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteJsonReportsEncodingFailure(t *testing.T) {
+	req := httptest.NewRequest("GET", "/v2/channels/UCchannel", nil)
+	w := httptest.NewRecorder()
+	// NaN has no JSON encoding.
+	writeJson(w, req, http.StatusOK, map[string]float64{"value": math.NaN()})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != problemContentType {
+		t.Errorf("Content-Type = %q, want %q", contentType, problemContentType)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body is not JSON: %s", err)
+	}
+	if problem.Status != http.StatusInternalServerError || problem.Code != CodeInternalError {
+		t.Errorf("problem = %+v", problem)
+	}
+	if problem.Instance != "/v2/channels/UCchannel" {
+		t.Errorf("instance = %q, want the request path", problem.Instance)
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	for _, filename := range []string{
+		"UCchannel.csv",
+		"UC chan;nel.csv",
+		`UC"channel\.csv`,
+		"UC\r\nSet-Cookie: a=b.csv",
+		"UCkanäl.m3u",
+	} {
+		header := contentDisposition("attachment", filename)
+		if strings.ContainsAny(header, "\r\n") {
+			t.Errorf("contentDisposition(%q) = %q spans lines", filename, header)
+		}
+
+		disposition, params, err := mime.ParseMediaType(header)
+		if err != nil || disposition != "attachment" || params["filename"] != filename {
+			t.Errorf(
+				"contentDisposition(%q) = %q, which parses as %q, %q, %v",
+				filename, header, disposition, params["filename"], err,
+			)
+		}
+	}
+
+	if got := contentDisposition("inline", "UCchannel.ics"); got != "inline; filename=UCchannel.ics" {
+		t.Errorf("contentDisposition = %q", got)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type GrowthSample struct {
+	Time       time.Time `json:"time"`
+	Value      int64     `json:"value"`
+	Delta      int64     `json:"delta"`
+	RatePerDay float64   `json:"ratePerDay"`
+	GrowthRate float64   `json:"growthRate"`
+}
+
+type VideoViewHistoryResponse struct {
+	VideoId string          `json:"videoId"`
+	Count   int             `json:"count"`
+	Samples []*GrowthSample `json:"samples"`
+}
+
+type SubscriberHistoryResponse struct {
+	Channel *ChannelResource `json:"channel"`
+	Tracked bool             `json:"tracked"`
+	Count   int              `json:"count"`
+	Samples []*GrowthSample  `json:"samples"`
+}
+
+type TrackedChannelsResponse struct {
+	Count    int      `json:"count"`
+	Channels []string `json:"channels"`
+}
+
+// Each sample's delta and rates are measured against the sample before it.
+func growthSamples(times []time.Time, values []int64) []*GrowthSample {
+	samples := make([]*GrowthSample, len(values))
+
+	for i, value := range values {
+		sample := &GrowthSample{Time: times[i], Value: value}
+
+		if i > 0 {
+			sample.Delta = value - values[i-1]
+
+			days := times[i].Sub(times[i-1]).Hours() / 24
+			if days > 0 {
+				sample.RatePerDay = float64(sample.Delta) / days
+			}
+			if values[i-1] != 0 {
+				sample.GrowthRate = float64(sample.Delta) / float64(values[i-1])
+			}
+		}
+
+		samples[i] = sample
+	}
+
+	return samples
+}
+
+func (server *Server) isTracked(channelId string) bool {
+	for _, tracked := range server.store.TrackedChannels() {
+		if tracked == channelId {
+			return true
+		}
+	}
+	return false
+}
+
+func (server *Server) GetVideoViewHistoryV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	videoId := mux.Vars(req)["id"]
+	snapshots := server.store.VideoSnapshots(videoId)
+
+	times := make([]time.Time, len(snapshots))
+	values := make([]int64, len(snapshots))
+	for i, snapshot := range snapshots {
+		times[i] = snapshot.Time
+		values[i] = snapshot.ViewCount
+	}
+
+	writeJson(w, req, http.StatusOK, VideoViewHistoryResponse{
+		VideoId: videoId,
+		Count:   len(snapshots),
+		Samples: growthSamples(times, values),
+	})
+}
+
+func (server *Server) GetSubscriberHistoryV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, ok := server.getChannelResource(w, req, channelId)
+	if !ok {
+		return
+	}
+
+	snapshots := server.store.ChannelSnapshots(channelId)
+
+	// Samples taken while the channel hid its subscriber count are left out,
+	// so growth is measured between the counts that are known.
+	times := make([]time.Time, 0, len(snapshots))
+	values := make([]int64, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.SubscriberCount == nil {
+			continue
+		}
+		times = append(times, snapshot.Time)
+		values = append(values, *snapshot.SubscriberCount)
+	}
+
+	writeJson(w, req, http.StatusOK, SubscriberHistoryResponse{
+		Channel: channel,
+		Tracked: server.isTracked(channelId),
+		Count:   len(values),
+		Samples: growthSamples(times, values),
+	})
+}
+
+func (server *Server) GetTrackedChannelsV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channels := server.store.TrackedChannels()
+
+	writeJson(w, req, http.StatusOK, TrackedChannelsResponse{
+		Count:    len(channels),
+		Channels: channels,
+	})
+}
+
+func (server *Server) TrackChannelV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channelId := mux.Vars(req)["id"]
+
+	// Fail early on channels the Data API does not know about.
+	_, err := server.youtubeFor(req).GetChannel(channelId)
+	if err != nil {
+		writeError(w, req, err, "fetching channel")
+		return
+	}
+
+	server.store.Track(channelId)
+	server.sampler.SampleNow(channelId)
+
+	writeMessage(
+		w,
+		req,
+		http.StatusAccepted,
+		fmt.Sprintf("Channel %s is now tracked. The first sample is being recorded.", channelId),
+	)
+}
+
+func (server *Server) UntrackChannelV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channelId := mux.Vars(req)["id"]
+
+	if !server.store.Untrack(channelId) {
+		writeProblem(
+			w, req, http.StatusNotFound, CodeNotFound,
+			fmt.Sprintf("Channel %s is not tracked.", channelId),
+		)
+		return
+	}
+
+	err := server.store.Flush()
+	if err != nil {
+		writeError(w, req, err, "saving tracked channels")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
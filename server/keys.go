@@ -0,0 +1,212 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"yt_search_server/store"
+
+	"github.com/gorilla/mux"
+)
+
+const maxClientKeyNameLength = 100
+
+type ClientKeyResponse struct {
+	Id             string     `json:"id"`
+	Name           string     `json:"name"`
+	Scopes         []string   `json:"scopes"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	RateLimit      float64    `json:"rateLimit,omitempty"`
+	RateLimitBurst int        `json:"rateLimitBurst,omitempty"`
+	// Key is only returned when the key is created.
+	Key string `json:"key,omitempty"`
+}
+
+type ClientKeysResponse struct {
+	Count int                  `json:"count"`
+	Keys  []*ClientKeyResponse `json:"keys"`
+}
+
+type CreateClientKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt or ExpiresIn, a duration such as 720h, limit the key's life.
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	ExpiresIn      string     `json:"expiresIn"`
+	RateLimit      float64    `json:"rateLimit"`
+	RateLimitBurst int        `json:"rateLimitBurst"`
+}
+
+func newClientKeyResponse(key store.ClientKey, now time.Time) *ClientKeyResponse {
+	return &ClientKeyResponse{
+		Id:             key.Id,
+		Name:           key.Name,
+		Scopes:         key.Scopes,
+		Active:         key.Active(now),
+		CreatedAt:      key.CreatedAt,
+		ExpiresAt:      key.ExpiresAt,
+		RevokedAt:      key.RevokedAt,
+		RateLimit:      key.RateLimit,
+		RateLimitBurst: key.RateLimitBurst,
+	}
+}
+
+// newClientKeyToken returns a public ID for a new key and the key itself.
+func newClientKeyToken() (string, string, error) {
+	random := make([]byte, 8+32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", "", err
+	}
+	id := hex.EncodeToString(random[:8])
+	token := clientKeyPrefix + base64.RawURLEncoding.EncodeToString(random[8:])
+	return id, token, nil
+}
+
+// parseCreateClientKeyRequest validates the request and turns it into a key
+// without its ID or hash.
+func parseCreateClientKeyRequest(req *http.Request, now time.Time) (store.ClientKey, error) {
+	var body CreateClientKeyRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, req.Body, 1<<16))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&body)
+	if err != nil {
+		return store.ClientKey{}, fmt.Errorf("invalid request body: %s", err)
+	}
+
+	key := store.ClientKey{
+		Name:           strings.TrimSpace(body.Name),
+		Scopes:         []string{},
+		CreatedAt:      now,
+		RateLimit:      body.RateLimit,
+		RateLimitBurst: body.RateLimitBurst,
+	}
+
+	if key.Name == "" || len(key.Name) > maxClientKeyNameLength {
+		return key, fmt.Errorf("name must be 1 to %d characters long", maxClientKeyNameLength)
+	}
+
+	if len(body.Scopes) == 0 {
+		return key, fmt.Errorf("scopes must list at least one of %s", strings.Join(scopes, ", "))
+	}
+	requested := make(map[string]bool)
+	for _, scope := range body.Scopes {
+		requested[scope] = true
+	}
+	for _, scope := range scopes {
+		if requested[scope] {
+			key.Scopes = append(key.Scopes, scope)
+			delete(requested, scope)
+		}
+	}
+	for scope := range requested {
+		return key, fmt.Errorf("scope %q is not one of %s", scope, strings.Join(scopes, ", "))
+	}
+
+	if body.ExpiresAt != nil && body.ExpiresIn != "" {
+		return key, fmt.Errorf("only one of expiresAt and expiresIn may be given")
+	}
+	if body.ExpiresIn != "" {
+		expiresIn, err := time.ParseDuration(body.ExpiresIn)
+		if err != nil || expiresIn <= 0 {
+			return key, fmt.Errorf("expiresIn must be a positive duration such as 720h")
+		}
+		expiresAt := now.Add(expiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+	if body.ExpiresAt != nil {
+		if !body.ExpiresAt.After(now) {
+			return key, fmt.Errorf("expiresAt must be in the future")
+		}
+		key.ExpiresAt = body.ExpiresAt
+	}
+
+	if key.RateLimit < 0 || key.RateLimitBurst < 0 {
+		return key, fmt.Errorf("rateLimit and rateLimitBurst must not be negative")
+	}
+
+	return key, nil
+}
+
+func (server *Server) GetClientKeysV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	now := time.Now()
+	keys := server.store.ClientKeys()
+
+	resp := ClientKeysResponse{
+		Count: len(keys),
+		Keys:  make([]*ClientKeyResponse, 0, len(keys)),
+	}
+	for _, key := range keys {
+		resp.Keys = append(resp.Keys, newClientKeyResponse(key, now))
+	}
+
+	writeJson(w, req, http.StatusOK, resp)
+}
+
+func (server *Server) CreateClientKeyV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	now := time.Now()
+
+	key, err := parseCreateClientKeyRequest(req, now)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	id, token, err := newClientKeyToken()
+	if err != nil {
+		writeError(w, req, err, "generating key")
+		return
+	}
+	key.Id = id
+	key.Hash = hashClientKey(token)
+
+	server.store.AddClientKey(key)
+
+	err = server.store.Flush()
+	if err != nil {
+		// The client never sees the key, so it must not start working later
+		// when some other flush succeeds.
+		server.store.RemoveClientKey(key.Id)
+		writeError(w, req, err, "saving key")
+		return
+	}
+
+	// The key itself is shown this once; only its hash is kept.
+	resp := newClientKeyResponse(key, now)
+	resp.Key = token
+	writeJson(w, req, http.StatusCreated, resp)
+}
+
+func (server *Server) RevokeClientKeyV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	id := mux.Vars(req)["id"]
+
+	if !server.store.RevokeClientKey(id, time.Now()) {
+		writeProblem(
+			w, req, http.StatusNotFound, CodeNotFound,
+			fmt.Sprintf("No active key has the ID %s.", id),
+		)
+		return
+	}
+
+	err := server.store.Flush()
+	if err != nil {
+		writeError(w, req, err, "saving keys")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
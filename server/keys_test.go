@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"yt_search_server/store"
+)
+
+func TestCreateClientKeyRollsBackWhenSaveFails(t *testing.T) {
+	dir := t.TempDir()
+	keys, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flush writes into the directory, so removing it makes the save fail.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(nil, keys, nil)
+	req := httptest.NewRequest(
+		"POST", "/v2/keys", strings.NewReader(`{"name": "test", "scopes": ["read"]}`),
+	)
+	w := httptest.NewRecorder()
+	server.CreateClientKeyV2(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusInternalServerError, w.Body)
+	}
+	if listed := keys.ClientKeys(); len(listed) != 0 {
+		t.Errorf("store still holds %d keys after the failed save", len(listed))
+	}
+}
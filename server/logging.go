@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"time"
+	"yt_search_server/logging"
+)
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(body []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	n, err := recorder.ResponseWriter.Write(body)
+	recorder.bytes += int64(n)
+	return n, err
+}
+
+// Flush keeps streamed exports streaming through the recorder.
+func (recorder *responseRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (recorder *responseRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// LogRequests tags each request with an ID, taken from X-Request-ID when the
+// client sends a usable one, and writes an access log line once it completes.
+func (server *Server) LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(logging.RequestIdHeader)
+		if !logging.ValidRequestId(id) {
+			id = logging.NewRequestId()
+		}
+
+		ctx := logging.WithRequest(req.Context(), id)
+		w.Header().Set(logging.RequestIdHeader, id)
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		defer func() {
+			recovered := recover()
+
+			status := recorder.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			logger := logging.FromContext(ctx).With(
+				"method", req.Method,
+				"uri", req.URL.RequestURI(),
+				"status", status,
+				"bytes", recorder.bytes,
+				"durationMs", float64(time.Since(start).Microseconds())/1000,
+				"upstreamCalls", logging.UpstreamCalls(ctx),
+				"remoteAddr", req.RemoteAddr,
+				"userAgent", req.UserAgent(),
+			)
+
+			if recovered != nil {
+				logger.Warn("Request aborted")
+				panic(recovered)
+			}
+			logger.Info("Request")
+		}()
+
+		next.ServeHTTP(recorder, req.WithContext(ctx))
+	})
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+func parseChannelIds(req *http.Request) []string {
+	channelIds := []string{}
+	for _, value := range req.URL.Query()["channels"] {
+		for _, channelId := range strings.Split(value, ",") {
+			channelId = strings.TrimSpace(channelId)
+			if channelId != "" {
+				channelIds = append(channelIds, channelId)
+			}
+		}
+	}
+	return channelIds
+}
+
+func (server *Server) GetMergedNeighborsV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channelIds := parseChannelIds(req)
+	if len(channelIds) == 0 {
+		writeBadRequest(w, req, "Query parameter 'channels' missing.")
+		return
+	}
+
+	options, err := parseChannelVideosOptions(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	videos, err := server.youtubeFor(req).GetMergedChannelVideos(
+		channelIds, mux.Vars(req)["videoId"], options,
+	)
+	if err != nil {
+		writeError(w, req, err, "fetching videos")
+		return
+	}
+
+	channelsById := make(map[string]*ChannelResource)
+	resources := make([]*VideoResource, 0, len(videos.Videos))
+
+	for _, metadata := range videos.Videos {
+		if _, ok := channelsById[metadata.ChannelId]; !ok {
+			channel, err := newChannelResource(metadataChannel(metadata))
+			if err != nil {
+				writeError(w, req, err, "reading channel")
+				return
+			}
+			channelsById[metadata.ChannelId] = channel
+		}
+
+		video, err := newVideoResource(metadata)
+		if err != nil {
+			writeError(w, req, err, "reading video metadata")
+			return
+		}
+		resources = append(resources, video)
+	}
+
+	channels := make([]*ChannelResource, 0, len(channelIds))
+	for _, channelId := range channelIds {
+		channel, ok := channelsById[channelId]
+		if !ok {
+			channel, ok = server.getChannelResource(w, req, channelId)
+			if !ok {
+				return
+			}
+			channelsById[channelId] = channel
+		}
+		channels = append(channels, channel)
+	}
+
+	writeJson(w, req, http.StatusOK, MergedNeighborsResponse{
+		Channels:       channels,
+		Position:       videos.Position,
+		TotalResults:   videos.TotalResults,
+		Count:          len(resources),
+		PreviousCursor: videos.PreviousAnchor,
+		NextCursor:     videos.NextAnchor,
+		Videos:         resources,
+	})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+	"yt_search_server/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequests = metrics.Default.NewCounterVec(
+		"http_requests_total",
+		"HTTP requests by route, method and status.",
+		"route", "method", "status",
+	)
+	httpRequestDuration = metrics.Default.NewHistogramVec(
+		"http_request_duration_seconds",
+		"Latency of HTTP requests by route, method and status.",
+		metrics.DurationBuckets,
+		"route", "method", "status",
+	)
+	httpRequestsInFlight = metrics.Default.NewGaugeVec(
+		"http_requests_in_flight",
+		"HTTP requests currently being served.",
+	)
+)
+
+func init() {
+	metrics.Default.NewGaugeFunc(
+		"go_goroutines",
+		"Goroutines that currently exist.",
+		func() float64 { return float64(runtime.NumGoroutine()) },
+	)
+
+	startTime := float64(time.Now().Unix())
+	metrics.Default.NewGaugeFunc(
+		"process_start_time_seconds",
+		"Start time of the process since the Unix epoch, in seconds.",
+		func() float64 { return startTime },
+	)
+}
+
+// MeasureRequests records request metrics labelled with the route template
+// rather than the path, so IDs in paths do not multiply the series.
+func (server *Server) MeasureRequests(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route := unmatchedRoute
+		var match mux.RouteMatch
+		if router.Match(req, &match) && match.Route != nil {
+			if template, err := match.Route.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		httpRequestsInFlight.With().Inc()
+		defer httpRequestsInFlight.With().Dec()
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		defer func() {
+			status := recorder.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			labels := []string{route, req.Method, strconv.Itoa(status)}
+			httpRequests.With(labels...).Inc()
+			httpRequestDuration.With(labels...).Observe(time.Since(start).Seconds())
+		}()
+
+		router.ServeHTTP(recorder, req)
+	})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+type OnThisDayResponse struct {
+	Channel *ChannelResource `json:"channel"`
+	*youtube.OnThisDay
+}
+
+func parseIntParam(req *http.Request, name string, defaultValue int) (int, error) {
+	value := req.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("Query parameter '%s' must be a number.", name)
+	}
+
+	return number, nil
+}
+
+func (server *Server) GetOnThisDayV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	location, err := parseTimezoneParam(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	today := time.Now().In(location)
+
+	month, err := parseIntParam(req, "month", int(today.Month()))
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	day, err := parseIntParam(req, "day", today.Day())
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	tolerance, err := parseIntParam(req, "tolerance", 0)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	err = youtube.ValidateOnThisDay(month, day, tolerance)
+	if err != nil {
+		writeError(w, req, err, "looking up uploads")
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, ok := server.getChannelResource(w, req, channelId)
+	if !ok {
+		return
+	}
+
+	onThisDay, err := server.youtubeFor(req).GetOnThisDay(channelId, month, day, tolerance, location)
+	if err != nil {
+		writeError(w, req, err, "looking up uploads")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, OnThisDayResponse{
+		Channel:   channel,
+		OnThisDay: onThisDay,
+	})
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOnThisDayRejectsInvalidDatesBeforeFetching(t *testing.T) {
+	// With no YouTube client, reaching the channel lookup would panic.
+	server := NewServer(nil, nil, nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/channels/{id}/onthisday", server.GetOnThisDayV2)
+
+	for _, query := range []string{
+		"month=13",
+		"month=0",
+		"month=2&day=30",
+		"month=4&day=31",
+		"day=0",
+		"tolerance=-1",
+		"tolerance=31",
+	} {
+		req := httptest.NewRequest("GET", "/v2/channels/UCchannel/onthisday?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"net/http"
+	"yt_search_server/openapi"
+)
+
+func (server *Server) GetOpenApi(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+	w.WriteHeader(http.StatusOK)
+	w.Write(openapi.Spec)
+}
+
+func (server *Server) GetDocs(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// The page is self-contained and may only call this server.
+	w.Header().Set(
+		"Content-Security-Policy",
+		"default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'",
+	)
+	w.WriteHeader(http.StatusOK)
+	w.Write(openapi.Docs)
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (server *Server) GetChannelPlaylists(w http.ResponseWriter, req *http.Request) {
+	setDeprecationHeaders(w, pathSuccessor(req))
+	setJsonContentType(w)
+
+	channelId := mux.Vars(req)["id"]
+	pageToken := req.URL.Query().Get("pageToken")
+
+	playlists, err := server.youtubeFor(req).GetChannelPlaylists(channelId, pageToken)
+	if err != nil {
+		writeError(w, req, err, "fetching playlists")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, playlists)
+}
+
+func (server *Server) GetVideoPlaylists(w http.ResponseWriter, req *http.Request) {
+	setDeprecationHeaders(w, pathSuccessor(req))
+	setJsonContentType(w)
+
+	vars := mux.Vars(req)
+
+	playlists, err := server.youtubeFor(req).GetVideoPlaylists(vars["id"], vars["videoId"])
+	if err != nil {
+		writeError(w, req, err, "looking up playlists")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, playlists)
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"yt_search_server/logging"
+	"yt_search_server/youtube"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem codes are part of the API. Clients may branch on them, so they never
+// change meaning once released.
+const (
+	CodeBadRequest            = "bad_request"
+	CodeUnauthorized          = "unauthorized"
+	CodeForbidden             = "forbidden"
+	CodeNotFound              = "not_found"
+	CodeMethodNotAllowed      = "method_not_allowed"
+	CodeRateLimited           = "rate_limited"
+	CodeUpstreamError         = "upstream_error"
+	CodeUpstreamUnavailable   = "upstream_unavailable"
+	CodeUpstreamQuotaExceeded = "upstream_quota_exceeded"
+	CodeUpstreamTimeout       = "upstream_timeout"
+	CodeInternalError         = "internal_error"
+)
+
+// A Problem is an RFC 7807 problem details object, extended with a stable
+// code and the ID of the request it answers.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestId string `json:"requestId,omitempty"`
+}
+
+func writeProblem(
+	w http.ResponseWriter, req *http.Request, status int, code string, detail string,
+) {
+	header := w.Header()
+	header.Set("Content-Type", problemContentType)
+	// Success headers set before the failure no longer describe the body.
+	header.Del("Content-Disposition")
+	header.Del("Cache-Control")
+
+	// A Problem holds only strings and numbers, so it always encodes.
+	body, _ := json.Marshal(Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Code:      code,
+		Detail:    detail,
+		Instance:  req.URL.Path,
+		RequestId: logging.RequestId(req.Context()),
+	})
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeBadRequest(w http.ResponseWriter, req *http.Request, detail string) {
+	writeProblem(w, req, http.StatusBadRequest, CodeBadRequest, sentence(detail))
+}
+
+// writeError answers with the problem err amounts to. Errors the client
+// caused are shown as they are; anything else is logged and described only
+// by what was being done, so upstream and internal details stay private.
+func writeError(w http.ResponseWriter, req *http.Request, err error, doing string) {
+	status, code, detail := http.StatusInternalServerError, CodeInternalError,
+		fmt.Sprintf("Error while %s.", doing)
+
+	var upstream *youtube.UpstreamError
+	switch {
+	case errors.Is(err, youtube.ErrInvalidArgument):
+		status, code, detail = http.StatusBadRequest, CodeBadRequest, sentence(err.Error())
+	case errors.Is(err, youtube.ErrNotFound):
+		status, code, detail = http.StatusNotFound, CodeNotFound, sentence(err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		status, code = http.StatusGatewayTimeout, CodeUpstreamTimeout
+		detail = fmt.Sprintf("Error while %s: the YouTube API did not answer in time.", doing)
+	case errors.As(err, &upstream) && upstream.QuotaExceeded():
+		status, code = http.StatusServiceUnavailable, CodeUpstreamQuotaExceeded
+		detail = "The YouTube API quota is used up. Try again after it resets at midnight Pacific time."
+	case errors.As(err, &upstream) && upstream.Status == http.StatusNotFound:
+		status, code = http.StatusNotFound, CodeNotFound
+		detail = fmt.Sprintf("Error while %s: YouTube has no such resource.", doing)
+	case errors.As(err, &upstream) && upstream.Status == 0:
+		status, code = http.StatusBadGateway, CodeUpstreamUnavailable
+		detail = fmt.Sprintf("Error while %s: the YouTube API could not be reached.", doing)
+	case errors.As(err, &upstream):
+		status, code = http.StatusBadGateway, CodeUpstreamError
+		detail = fmt.Sprintf(
+			"Error while %s: the YouTube API answered %s with status %d.",
+			doing, upstream.Endpoint, upstream.Status,
+		)
+	}
+
+	if status >= http.StatusInternalServerError {
+		logging.FromContext(req.Context()).Error(
+			"Request failed", "code", code, "doing", doing, "error", err,
+		)
+	}
+	writeProblem(w, req, status, code, detail)
+}
+
+// sentence capitalises a message and ends it with a full stop.
+func sentence(message string) string {
+	if message == "" {
+		return message
+	}
+	if last := message[len(message)-1]; last != '.' && last != '?' && last != '!' {
+		message += "."
+	}
+	if first := message[0]; first >= 'a' && first <= 'z' {
+		message = string(first-'a'+'A') + message[1:]
+	}
+	return message
+}
+
+func (server *Server) NotFound(w http.ResponseWriter, req *http.Request) {
+	writeProblem(
+		w, req, http.StatusNotFound, CodeNotFound,
+		fmt.Sprintf("No route matches %s.", req.URL.Path),
+	)
+}
+
+func (server *Server) MethodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	writeProblem(
+		w, req, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+		fmt.Sprintf("Method %s is not allowed on %s.", req.Method, req.URL.Path),
+	)
+}
+
+// RecoverPanics turns a panicking handler into a 500 problem instead of a
+// dropped connection. A panic after the response has started can only be
+// logged, and http.ErrAbortHandler is passed on, as handlers use it to cut
+// off a response on purpose.
+func (server *Server) RecoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		recorder := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logging.FromContext(req.Context()).Error(
+				"Handler panicked",
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			if recorder.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+			writeProblem(
+				recorder, req, http.StatusInternalServerError, CodeInternalError,
+				"The server failed to handle the request.",
+			)
+		}()
+
+		next.ServeHTTP(recorder, req)
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// useTransport answers YouTube Data API calls with fn for the rest of the
+// test.
+func useTransport(t *testing.T, fn roundTripFunc) {
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = fn
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+}
+
+func jsonResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func TestQuotaErrorsSurviveWrapping(t *testing.T) {
+	// The channel and its playlists load, but indexing the playlists' items
+	// runs out of quota, and the error is wrapped on its way out of the index.
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.Contains(req.URL.Path, "/channels"):
+			return jsonResponse(req, http.StatusOK, `{"items": [{
+				"snippet": {"title": "Channel", "customUrl": "@channel",
+					"thumbnails": {"medium": {"url": "https://example.com/thumb.jpg"}}},
+				"statistics": {"videoCount": "1", "viewCount": "1"}
+			}]}`), nil
+		case strings.Contains(req.URL.Path, "/playlistItems"):
+			return jsonResponse(req, http.StatusForbidden,
+				`{"error": {"errors": [{"reason": "quotaExceeded"}]}}`), nil
+		}
+		return jsonResponse(req, http.StatusOK,
+			`{"items": [{"id": "PLlist", "snippet": {"title": "List"}}]}`), nil
+	})
+
+	server := NewServer(
+		youtube.NewYouTubeService(youtube.Options{ApiKeys: []string{"key"}}), nil, nil,
+	)
+	router := mux.NewRouter()
+	router.HandleFunc("/v2/channels/{id}/videos/{videoId}/playlists", server.GetVideoPlaylistsV2)
+
+	req := httptest.NewRequest("GET", "/v2/channels/UCchannel/videos/abc/playlists", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusServiceUnavailable, w.Body)
+	}
+	var problem struct{ Code string }
+	json.NewDecoder(w.Body).Decode(&problem)
+	if problem.Code != CodeUpstreamQuotaExceeded {
+		t.Errorf("code = %q, want %q", problem.Code, CodeUpstreamQuotaExceeded)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+// YouTube ignores ids beyond the 50th in a watch_videos link.
+const watchVideosChunkSize = 50
+
+type queueFormat struct {
+	contentType string
+	extension   string
+	write       func(builder *strings.Builder, channel *youtube.Channel, queue []*youtube.QueueItem)
+}
+
+// Titles are written on a single line in every format.
+var singleLineReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+var queueFormats = map[string]queueFormat{
+	"m3u": {
+		contentType: "audio/x-mpegurl; charset=utf-8",
+		extension:   "m3u",
+		write: func(builder *strings.Builder, channel *youtube.Channel, queue []*youtube.QueueItem) {
+			builder.WriteString("#EXTM3U\n")
+			builder.WriteString(fmt.Sprintf("#PLAYLIST:%s\n", singleLineReplacer.Replace(channel.ChannelTitle)))
+			for _, item := range queue {
+				duration := item.Duration
+				if duration <= 0 {
+					duration = -1
+				}
+				builder.WriteString(fmt.Sprintf(
+					"#EXTINF:%d,%s\n%s\n",
+					duration,
+					singleLineReplacer.Replace(item.Title),
+					watchUrl(item.VideoId),
+				))
+			}
+		},
+	},
+	"urls": {
+		contentType: "text/plain; charset=utf-8",
+		extension:   "txt",
+		write: func(builder *strings.Builder, channel *youtube.Channel, queue []*youtube.QueueItem) {
+			for _, item := range queue {
+				builder.WriteString(watchUrl(item.VideoId) + "\n")
+			}
+		},
+	},
+	"ytdlp": {
+		contentType: "text/plain; charset=utf-8",
+		extension:   "txt",
+		write: func(builder *strings.Builder, channel *youtube.Channel, queue []*youtube.QueueItem) {
+			builder.WriteString(fmt.Sprintf(
+				"# %s: %d videos in timeline order\n",
+				singleLineReplacer.Replace(channel.ChannelTitle),
+				len(queue),
+			))
+			builder.WriteString("# yt-dlp --batch-file <this file>\n")
+			for _, item := range queue {
+				builder.WriteString(fmt.Sprintf(
+					"# %d. %s\n%s\n",
+					item.Position+1,
+					singleLineReplacer.Replace(item.Title),
+					watchUrl(item.VideoId),
+				))
+			}
+		},
+	},
+	"watchvideos": {
+		contentType: "text/plain; charset=utf-8",
+		extension:   "txt",
+		write: func(builder *strings.Builder, channel *youtube.Channel, queue []*youtube.QueueItem) {
+			for start := 0; start < len(queue); start += watchVideosChunkSize {
+				end := start + watchVideosChunkSize
+				if end > len(queue) {
+					end = len(queue)
+				}
+				videoIds := make([]string, 0, end-start)
+				for _, item := range queue[start:end] {
+					videoIds = append(videoIds, item.VideoId)
+				}
+				builder.WriteString(
+					"https://www.youtube.com/watch_videos?video_ids=" +
+						strings.Join(videoIds, ",") + "\n",
+				)
+			}
+		},
+	},
+}
+
+func (server *Server) GetQueueV2(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("format")
+	if name == "" {
+		name = "m3u"
+	}
+
+	format, ok := queueFormats[name]
+	if !ok {
+		writeBadRequest(w, req, "Query parameter 'format' must be one of m3u, urls, ytdlp, watchvideos.")
+		return
+	}
+
+	options, err := parseChannelVideosOptions(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	videoId := req.URL.Query().Get("videoId")
+	fromVideoId := req.URL.Query().Get("from")
+	toVideoId := req.URL.Query().Get("to")
+
+	if videoId != "" && (fromVideoId != "" || toVideoId != "") {
+		writeBadRequest(w, req, "Query parameter 'videoId' cannot be combined with 'from' or 'to'.")
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, err := server.youtubeFor(req).GetChannel(channelId)
+	if err != nil {
+		writeError(w, req, err, "fetching channel")
+		return
+	}
+
+	queue, err := server.youtubeFor(req).GetQueue(channelId, options, videoId, fromVideoId, toVideoId)
+	if err != nil {
+		writeError(w, req, err, "fetching videos")
+		return
+	}
+
+	var builder strings.Builder
+	format.write(&builder, channel, queue)
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", contentDisposition("attachment", channelId+"."+format.extension))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(builder.String()))
+}
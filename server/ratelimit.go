@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"yt_search_server/logging"
+	"yt_search_server/metrics"
+	"yt_search_server/ratelimit"
+
+	"github.com/gorilla/mux"
+)
+
+var rateLimited = metrics.Default.NewCounterVec(
+	"http_rate_limited_total",
+	"Requests rejected by the rate limiter, by route.",
+	"route",
+)
+
+func ceilSeconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
+
+// LimitRequests charges each request the cost of its route against the
+// bucket of the client's key or address, answering 429 once the bucket runs
+// dry. Allowlisted clients are not charged. With the limiter off, only keys
+// with their own rate limit are charged.
+func (server *Server) LimitRequests(limiter *ratelimit.Limiter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			route := routeTemplate(req)
+			cost := limiter.Cost(route)
+			clientIp := limiter.ClientIp(req)
+			if cost == 0 || limiter.Allowlisted(clientIp, presentedKey(req)) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			// Clients with a key are limited by key, wherever they connect from.
+			client := clientIp
+			allowance := ratelimit.Allowance{}
+			if key, ok := clientKeyFrom(req.Context()); ok {
+				client = "key:" + key.Id
+				allowance = ratelimit.Allowance{
+					PerMinute: key.RateLimit,
+					Burst:     key.RateLimitBurst,
+				}
+			}
+
+			if !limiter.Limits(allowance) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			decision := limiter.TakeAllowance(client, cost, allowance, time.Now())
+
+			w.Header().Set("RateLimit-Policy", fmt.Sprintf(
+				"%d;w=%s", decision.Limit, ceilSeconds(decision.Window),
+			))
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			w.Header().Set("RateLimit-Reset", ceilSeconds(decision.Reset))
+
+			if !decision.Allowed {
+				rateLimited.With(route).Inc()
+				logging.FromContext(req.Context()).Info(
+					"Rate limited", "client", client, "route", route, "cost", cost,
+				)
+
+				w.Header().Set("Retry-After", ceilSeconds(decision.RetryAfter))
+				writeProblem(
+					w, req, http.StatusTooManyRequests, CodeRateLimited,
+					fmt.Sprintf(
+						"Rate limit exceeded, retry in %s seconds.",
+						ceilSeconds(decision.RetryAfter),
+					),
+				)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"yt_search_server/ratelimit"
+	"yt_search_server/store"
+)
+
+func TestLimitRequestsAppliesKeyLimitsWithLimiterOff(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.Options{DefaultCost: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewServer(nil, nil, nil).LimitRequests(limiter)(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}),
+	)
+
+	tests := []struct {
+		name string
+		key  *store.ClientKey
+		// allowed is how many requests in a row succeed; -1 is all of them.
+		allowed int
+	}{
+		{name: "no key", allowed: -1},
+		{name: "key without limits", key: &store.ClientKey{Id: "plain"}, allowed: -1},
+		{name: "key with rate", key: &store.ClientKey{Id: "rate", RateLimit: 3}, allowed: 3},
+		{
+			name:    "key with rate and burst",
+			key:     &store.ClientKey{Id: "burst", RateLimit: 3, RateLimitBurst: 2},
+			allowed: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			succeeded := 0
+			for i := 0; i < 10; i++ {
+				req := httptest.NewRequest("GET", "/v2/tracked", nil)
+				if test.key != nil {
+					req = req.WithContext(withClientKey(req.Context(), *test.key))
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+				if w.Code == http.StatusOK {
+					succeeded++
+				} else if w.Code != http.StatusTooManyRequests {
+					t.Fatalf("status = %d", w.Code)
+				}
+			}
+
+			want := test.allowed
+			if want == -1 {
+				want = 10
+			}
+			if succeeded != want {
+				t.Errorf("%d of 10 requests succeeded, want %d", succeeded, want)
+			}
+		})
+	}
+}
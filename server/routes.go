@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"yt_search_server/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterRoutes adds every endpoint to router. openapi/openapi.json
+// describes the same routes, and the tests hold the two together. Routes that
+// crawl a channel's whole timeline are given longer than the write timeout.
+func (server *Server) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/", server.GetHome).Methods("GET")
+	router.HandleFunc("/healthz", server.GetHealthz).Methods("GET")
+	router.HandleFunc("/readyz", server.GetReadyz).Methods("GET")
+	router.Handle("/metrics", metrics.Default.Handler()).Methods("GET")
+	router.HandleFunc("/openapi.json", server.GetOpenApi).Methods("GET")
+	router.HandleFunc("/docs", server.GetDocs).Methods("GET")
+	router.HandleFunc("/metadata/", server.GetMetadata).Methods("GET")
+	router.HandleFunc("/videos/", crawling(server.GetVideos)).Methods("GET")
+	router.HandleFunc("/channels/{id}/playlists", server.GetChannelPlaylists).Methods("GET")
+	router.HandleFunc("/channels/{id}/videos/{videoId}/playlists", server.GetVideoPlaylists).Methods("GET")
+
+	v2 := router.PathPrefix("/v2").Subrouter()
+	v2.HandleFunc("/channels/{id}", server.GetChannelV2).Methods("GET")
+	v2.HandleFunc("/channels/{id}/calendar", crawling(server.GetChannelCalendarV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/export", crawling(server.ExportChannelV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/feed", crawling(server.GetRewatchFeedV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/onthisday", crawling(server.GetOnThisDayV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/playlists", server.GetChannelPlaylistsV2).Methods("GET")
+	v2.HandleFunc("/channels/{id}/queue", crawling(server.GetQueueV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/search", crawling(server.SearchChannelV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/series", crawling(server.GetChannelSeriesV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/stats/cadence", crawling(server.GetChannelCadenceV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/stats/subscribers", server.GetSubscriberHistoryV2).Methods("GET")
+	v2.HandleFunc("/channels/{id}/videos/{videoId}/neighbors", crawling(server.GetNeighborsV2)).Methods("GET")
+	v2.HandleFunc("/channels/{id}/videos/{videoId}/playlists", server.GetVideoPlaylistsV2).Methods("GET")
+	v2.HandleFunc("/channels/{id}/videos/{videoId}/series", crawling(server.GetVideoSeriesV2)).Methods("GET")
+	v2.HandleFunc("/videos/{id}", server.GetVideoV2).Methods("GET")
+	v2.HandleFunc("/videos/{id}/stats/views", server.GetVideoViewHistoryV2).Methods("GET")
+	v2.HandleFunc("/keys", server.GetClientKeysV2).Methods("GET")
+	v2.HandleFunc("/keys", server.CreateClientKeyV2).Methods("POST")
+	v2.HandleFunc("/keys/{id}", server.RevokeClientKeyV2).Methods("DELETE")
+	v2.HandleFunc("/tracked", server.GetTrackedChannelsV2).Methods("GET")
+	v2.HandleFunc("/tracked/{id}", server.TrackChannelV2).Methods("PUT")
+	v2.HandleFunc("/tracked/{id}", server.UntrackChannelV2).Methods("DELETE")
+	v2.HandleFunc("/timeline/videos/{videoId}/neighbors", crawling(server.GetMergedNeighborsV2)).Methods("GET")
+
+	router.NotFoundHandler = http.HandlerFunc(server.NotFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(server.MethodNotAllowed)
+}
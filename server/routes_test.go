@@ -0,0 +1,18 @@
+package server
+
+import (
+	"testing"
+	"yt_search_server/openapi"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRoutesMatchOpenApiDocument(t *testing.T) {
+	router := mux.NewRouter()
+	router.StrictSlash(true)
+	NewServer(nil, nil, nil).RegisterRoutes(router)
+
+	if err := openapi.Check(router); err != nil {
+		t.Fatalf("openapi/openapi.json and RegisterRoutes disagree: %s", err)
+	}
+}
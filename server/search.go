@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultSearchLimit = 20
+
+const maxSearchLimit = 100
+
+type SearchResponse struct {
+	Channel *ChannelResource `json:"channel"`
+	*youtube.SearchResults
+}
+
+func (server *Server) SearchChannelV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	query := req.URL.Query().Get("q")
+	if query == "" {
+		writeBadRequest(w, req, "Query parameter 'q' missing.")
+		return
+	}
+
+	limit, err := parseLimitParam(req, defaultSearchLimit, maxSearchLimit)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, ok := server.getChannelResource(w, req, channelId)
+	if !ok {
+		return
+	}
+
+	results, err := server.youtubeFor(req).SearchChannel(channelId, query, limit)
+	if err != nil {
+		writeError(w, req, err, "searching channel")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, SearchResponse{
+		Channel:       channel,
+		SearchResults: results,
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+type SeriesListResponse struct {
+	Channel *ChannelResource  `json:"channel"`
+	Count   int               `json:"count"`
+	Series  []*youtube.Series `json:"series"`
+}
+
+type VideoSeriesResponse struct {
+	Channel *ChannelResource `json:"channel"`
+	*youtube.VideoSeries
+}
+
+func (server *Server) GetChannelSeriesV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, ok := server.getChannelResource(w, req, channelId)
+	if !ok {
+		return
+	}
+
+	series, err := server.youtubeFor(req).GetChannelSeries(channelId)
+	if err != nil {
+		writeError(w, req, err, "detecting series")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, SeriesListResponse{
+		Channel: channel,
+		Count:   len(series),
+		Series:  series,
+	})
+}
+
+func (server *Server) GetVideoSeriesV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	vars := mux.Vars(req)
+
+	channel, ok := server.getChannelResource(w, req, vars["id"])
+	if !ok {
+		return
+	}
+
+	series, err := server.youtubeFor(req).GetVideoSeries(vars["id"], vars["videoId"])
+	if errors.Is(err, youtube.ErrVideoNotInSeries) {
+		writeProblem(
+			w, req, http.StatusNotFound, CodeNotFound,
+			fmt.Sprintf("Video %s is not part of a detected series.", vars["videoId"]),
+		)
+		return
+	}
+	if err != nil {
+		writeError(w, req, err, "detecting series")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, VideoSeriesResponse{
+		Channel:     channel,
+		VideoSeries: series,
+	})
+}
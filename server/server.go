@@ -1,118 +1,83 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
+	"yt_search_server/sampler"
+	"yt_search_server/store"
 	"yt_search_server/youtube"
 )
 
 type Server struct {
 	youtube *youtube.YouTube
+	store   *store.Store
+	sampler *sampler.Sampler
 }
 
-func NewServer(youtube *youtube.YouTube) *Server {
-	return &Server{youtube: youtube}
+func NewServer(
+	youtube *youtube.YouTube, store *store.Store, sampler *sampler.Sampler,
+) *Server {
+	return &Server{youtube: youtube, store: store, sampler: sampler}
+}
+
+// youtubeFor binds the YouTube client to the request, so its upstream calls
+// are attributed to it and abandoned if the client goes away.
+func (server *Server) youtubeFor(req *http.Request) *youtube.YouTube {
+	return server.youtube.WithContext(req.Context())
 }
 
 func (server *Server) GetHome(w http.ResponseWriter, req *http.Request) {
-	log.Printf("Received %s request on %s\n", req.Method, req.URL)
 	fmt.Fprint(w, "Welcome to the YouTube Search Server!")
 }
 
 func (server *Server) GetMetadata(w http.ResponseWriter, req *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Content-Type", "application/json")
-
-	log.Printf("Received %s request on %s\n", req.Method, req.URL)
+	setDeprecationHeaders(w, metadataSuccessor(req))
+	setJsonContentType(w)
 
 	idOrUrl := req.URL.Query().Get("idorurl")
 	if idOrUrl == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		resp := make(map[string]string)
-		resp["message"] = "Bad Request. Query parameter 'idorurl' missing."
-		jsonResp, err := json.Marshal(resp)
-		if err != nil {
-			log.Fatal("Error parsing JSON.\n")
-		}
-		w.Write(jsonResp)
+		writeBadRequest(w, req, "Query parameter 'idorurl' missing.")
 		return
 	}
 
-	metadata, err := server.youtube.GetVideoMetadata(idOrUrl)
+	metadata, err := server.youtubeFor(req).GetVideoMetadata(idOrUrl)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		resp := make(map[string]string)
-		resp["message"] = fmt.Sprintf("Error while fetching video metadata: %s", err)
-		jsonResp, err := json.Marshal(resp)
-		if err != nil {
-			log.Fatal("Error parsing JSON.\n")
-		}
-		w.Write(jsonResp)
+		writeError(w, req, err, "fetching video metadata")
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	jsonResp, err := json.Marshal(metadata)
-	if err != nil {
-		log.Fatal("Error parsing JSON.\n")
-	}
-	w.Write(jsonResp)
+
+	writeJson(w, req, http.StatusOK, metadata)
 }
 
 func (server *Server) GetVideos(w http.ResponseWriter, req *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Content-Type", "application/json")
-
-	log.Printf("Received %s request on %s\n", req.Method, req.URL)
+	setDeprecationHeaders(w, videosSuccessor(req))
+	setJsonContentType(w)
 
 	qpChannelId := req.URL.Query().Get("channelId")
 	if qpChannelId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		resp := make(map[string]string)
-		resp["message"] = "Bad Request. Query parameter 'id' missing."
-		jsonResp, err := json.Marshal(resp)
-		if err != nil {
-			log.Fatal("Error forming JSON.\n")
-		}
-		w.Write(jsonResp)
+		writeBadRequest(w, req, "Query parameter 'channelId' missing.")
 		return
 	}
 
 	qpVideoId := req.URL.Query().Get("videoId")
 	if qpVideoId == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		resp := make(map[string]string)
-		resp["message"] = "Bad Request. Query parameter 'videoId' missing."
-		jsonResp, err := json.Marshal(resp)
-		if err != nil {
-			log.Fatal("Error forming JSON.\n")
-		}
-		w.Write(jsonResp)
+		writeBadRequest(w, req, "Query parameter 'videoId' missing.")
 		return
 	}
 
-	videos, err := server.youtube.GetChannelVideos(
-		qpChannelId, qpVideoId,
-	)
+	options, err := parseChannelVideosOptions(req)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		resp := make(map[string]string)
-		resp["message"] = fmt.Sprintf("Error while fetching videos: %s", err)
-		jsonResp, err := json.Marshal(resp)
-		if err != nil {
-			log.Fatal("Error forming JSON.\n")
-		}
-		w.Write(jsonResp)
+		writeBadRequest(w, req, err.Error())
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	jsonResp, err := json.Marshal(videos)
+	videos, err := server.youtubeFor(req).GetChannelVideos(
+		qpChannelId, qpVideoId, options,
+	)
 	if err != nil {
-		log.Fatal("Error forming JSON.\n")
+		writeError(w, req, err, "fetching videos")
+		return
 	}
-	w.Write(jsonResp)
+
+	writeJson(w, req, http.StatusOK, videos)
 }
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+type CadenceResponse struct {
+	Channel *ChannelResource `json:"channel"`
+	*youtube.Cadence
+}
+
+func parseTimezoneParam(req *http.Request) (*time.Location, error) {
+	name := req.URL.Query().Get("tz")
+	if name == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("Query parameter 'tz' is not a known time zone: %s.", name)
+	}
+
+	return location, nil
+}
+
+func (server *Server) GetChannelCadenceV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	location, err := parseTimezoneParam(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	options, err := parseChannelVideosOptions(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, ok := server.getChannelResource(w, req, channelId)
+	if !ok {
+		return
+	}
+
+	cadence, err := server.youtubeFor(req).GetChannelCadence(channelId, options, location)
+	if err != nil {
+		writeError(w, req, err, "computing upload cadence")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, CadenceResponse{
+		Channel: channel,
+		Cadence: cadence,
+	})
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseTimezoneParam(t *testing.T) {
+	instant := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		tz      string
+		name    string
+		offset  int
+		wantErr bool
+	}{
+		{tz: "", name: "UTC", offset: 0},
+		{tz: "UTC", name: "UTC", offset: 0},
+		{tz: "Europe/Berlin", name: "Europe/Berlin", offset: 60 * 60},
+		{tz: "Asia/Kolkata", name: "Asia/Kolkata", offset: 5*60*60 + 30*60},
+		// Etc zones count their offsets the POSIX way, west of Greenwich.
+		{tz: "Etc/GMT+5", name: "Etc/GMT+5", offset: -5 * 60 * 60},
+		// Bare offsets are not zone names.
+		{tz: "UTC+5", wantErr: true},
+		{tz: "+05:30", wantErr: true},
+		{tz: "Mars/Olympus_Mons", wantErr: true},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/v2/channels/UCchannel/stats/cadence?tz="+url.QueryEscape(test.tz), nil)
+		location, err := parseTimezoneParam(req)
+		if (err != nil) != test.wantErr {
+			t.Errorf("tz=%q: error = %v, want error %v", test.tz, err, test.wantErr)
+			continue
+		}
+		if test.wantErr {
+			continue
+		}
+
+		_, offset := instant.In(location).Zone()
+		if location.String() != test.name || offset != test.offset {
+			t.Errorf("tz=%q: %s at offset %d, want %s at %d", test.tz, location, offset, test.name, test.offset)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"yt_search_server/youtube"
+)
+
+// A fakeVideo is an upload of the fake channel. Hidden videos are listed in
+// the uploads playlist but have no details, as after being made private.
+type fakeVideo struct {
+	title  string
+	hidden bool
+}
+
+// fakeUploadTime is when the fake channel's ith upload was published: daily
+// from the start of 2020.
+func fakeUploadTime(i int) time.Time {
+	return time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC).AddDate(0, 0, i)
+}
+
+func fakeVideoId(i int) string {
+	return fmt.Sprintf("video%04d", i)
+}
+
+// useChannel answers the Data API calls for a channel titled title whose
+// uploads are videos, oldest first, and returns a client for it.
+func useChannel(t *testing.T, title string, videos []fakeVideo) *youtube.YouTube {
+	quote := func(value string) string {
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	}
+
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch strings.TrimPrefix(req.URL.Path, "/youtube/v3/") {
+		case "channels/":
+			return jsonResponse(req, http.StatusOK, fmt.Sprintf(`{"items": [{
+				"snippet": {"title": %s, "customUrl": "@channel",
+					"thumbnails": {"medium": {"url": "https://example.com/channel.jpg"}}},
+				"statistics": {"videoCount": "%d", "viewCount": "1000"},
+				"contentDetails": {"relatedPlaylists": {"uploads": "UUchannel"}}
+			}]}`, quote(title), len(videos))), nil
+
+		case "playlistItems/":
+			first := 0
+			fmt.Sscanf(query.Get("pageToken"), "page%d", &first)
+			count := 50
+			if query.Get("maxResults") == "1" {
+				count = 1
+			}
+			items := []string{}
+			for i := first; i < first+count && i < len(videos); i++ {
+				items = append(items, fmt.Sprintf(
+					`{"contentDetails": {"videoId": %s, "videoPublishedAt": %s}}`,
+					quote(fakeVideoId(i)), quote(fakeUploadTime(i).Format(time.RFC3339)),
+				))
+			}
+			next := ""
+			if count > 1 && first+count < len(videos) {
+				next = fmt.Sprintf(`"nextPageToken": "page%d",`, first+count)
+			}
+			return jsonResponse(req, http.StatusOK, fmt.Sprintf(
+				`{%s "pageInfo": {"totalResults": %d}, "items": [%s]}`,
+				next, len(videos), strings.Join(items, ","),
+			)), nil
+
+		case "videos/":
+			items := []string{}
+			for _, videoId := range strings.Split(query.Get("id"), ",") {
+				var i int
+				if _, err := fmt.Sscanf(videoId, "video%d", &i); err != nil || i >= len(videos) || videos[i].hidden {
+					continue
+				}
+				items = append(items, fmt.Sprintf(`{
+					"id": %s,
+					"snippet": {"publishedAt": %s, "title": %s,
+						"thumbnails": {"medium": {"url": "https://example.com/%s.jpg"}}},
+					"contentDetails": {"duration": "PT10M", "definition": "hd", "caption": "false"},
+					"statistics": {"viewCount": "100", "likeCount": "10", "commentCount": "1"}
+				}`, quote(videoId), quote(fakeUploadTime(i).Format(time.RFC3339)), quote(videos[i].title), videoId))
+			}
+			return jsonResponse(req, http.StatusOK, fmt.Sprintf(`{"items": [%s]}`, strings.Join(items, ","))), nil
+		}
+
+		t.Errorf("unexpected upstream call to %s", req.URL.Path)
+		return jsonResponse(req, http.StatusNotFound, `{}`), nil
+	})
+
+	return youtube.NewYouTubeService(youtube.Options{ApiKeys: []string{"key"}})
+}
+
+// fakeVideos is count uploads titled "Video <i>".
+func fakeVideos(count int) []fakeVideo {
+	videos := make([]fakeVideo, count)
+	for i := range videos {
+		videos[i].title = fmt.Sprintf("Video %d", i)
+	}
+	return videos
+}
@@ -0,0 +1,373 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+	"yt_search_server/youtube"
+
+	"github.com/gorilla/mux"
+)
+
+type ChannelResource struct {
+	Id              string `json:"id"`
+	Title           string `json:"title"`
+	CustomUrl       string `json:"customUrl"`
+	Thumbnail       string `json:"thumbnail"`
+	SubscriberCount *int64 `json:"subscriberCount"`
+	VideoCount      int64  `json:"videoCount"`
+}
+
+type VideoResource struct {
+	Id              string    `json:"id"`
+	ChannelId       string    `json:"channelId"`
+	Title           string    `json:"title"`
+	Thumbnail       string    `json:"thumbnail"`
+	PublishedAt     time.Time `json:"publishedAt"`
+	Duration        int       `json:"duration"`
+	Definition      string    `json:"definition"`
+	Caption         bool      `json:"caption"`
+	LicensedContent bool      `json:"licensedContent"`
+	ViewCount       int64     `json:"viewCount"`
+	LikeCount       *int64    `json:"likeCount"`
+	CommentCount    *int64    `json:"commentCount"`
+}
+
+type PlaylistResource struct {
+	Id         string `json:"id"`
+	Title      string `json:"title"`
+	Thumbnail  string `json:"thumbnail"`
+	VideoCount int    `json:"videoCount"`
+}
+
+type PlaylistMembershipResource struct {
+	Playlist PlaylistResource `json:"playlist"`
+	Position int              `json:"position"`
+}
+
+type ChannelResponse struct {
+	Channel *ChannelResource `json:"channel"`
+}
+
+type VideoResponse struct {
+	Channel *ChannelResource `json:"channel"`
+	Video   *VideoResource   `json:"video"`
+}
+
+type NeighborsResponse struct {
+	Channel        *ChannelResource `json:"channel"`
+	Position       int              `json:"position"`
+	TotalResults   int              `json:"totalResults"`
+	Count          int              `json:"count"`
+	PreviousCursor string           `json:"previousCursor,omitempty"`
+	NextCursor     string           `json:"nextCursor,omitempty"`
+	Videos         []*VideoResource `json:"videos"`
+}
+
+type MergedNeighborsResponse struct {
+	Channels       []*ChannelResource `json:"channels"`
+	Position       int                `json:"position"`
+	TotalResults   int                `json:"totalResults"`
+	Count          int                `json:"count"`
+	PreviousCursor string             `json:"previousCursor,omitempty"`
+	NextCursor     string             `json:"nextCursor,omitempty"`
+	Videos         []*VideoResource   `json:"videos"`
+}
+
+type PlaylistsResponse struct {
+	Channel       *ChannelResource    `json:"channel"`
+	TotalResults  int                 `json:"totalResults"`
+	Count         int                 `json:"count"`
+	NextPageToken string              `json:"nextPageToken,omitempty"`
+	PrevPageToken string              `json:"prevPageToken,omitempty"`
+	Playlists     []*PlaylistResource `json:"playlists"`
+}
+
+type VideoPlaylistsResponse struct {
+	Channel   *ChannelResource              `json:"channel"`
+	VideoId   string                        `json:"videoId"`
+	Count     int                           `json:"count"`
+	Playlists []*PlaylistMembershipResource `json:"playlists"`
+}
+
+func newChannelResource(channel *youtube.Channel) (*ChannelResource, error) {
+	subscriberCount, err := youtube.ParseOptionalCount(channel.SubscriberCount)
+	if err != nil {
+		return nil, err
+	}
+
+	videoCount, err := youtube.ParseCount(channel.VideoCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChannelResource{
+		Id:              channel.ChannelId,
+		Title:           channel.ChannelTitle,
+		CustomUrl:       channel.ChannelCustomUrl,
+		Thumbnail:       channel.ChannelThumbnail,
+		SubscriberCount: subscriberCount,
+		VideoCount:      videoCount,
+	}, nil
+}
+
+func metadataChannel(metadata *youtube.VideoMetadata) *youtube.Channel {
+	return &youtube.Channel{
+		ChannelId:        metadata.ChannelId,
+		ChannelTitle:     metadata.ChannelTitle,
+		ChannelThumbnail: metadata.ChannelThumbnail,
+		ChannelCustomUrl: metadata.ChannelCustomUrl,
+		SubscriberCount:  metadata.SubscriberCount,
+		VideoCount:       metadata.VideoCount,
+	}
+}
+
+func newVideoResource(metadata *youtube.VideoMetadata) (*VideoResource, error) {
+	publishedAt, err := time.Parse(time.RFC3339Nano, metadata.PublishedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RFC 3339 timestamp %q", metadata.PublishedAt)
+	}
+
+	viewCount, err := youtube.ParseCount(metadata.ViewCount)
+	if err != nil {
+		return nil, err
+	}
+
+	likeCount, err := youtube.ParseOptionalCount(metadata.LikeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	commentCount, err := youtube.ParseOptionalCount(metadata.CommentCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VideoResource{
+		Id:              metadata.VideoId,
+		ChannelId:       metadata.ChannelId,
+		Title:           metadata.VideoTitle,
+		Thumbnail:       metadata.VideoThumbnail,
+		PublishedAt:     publishedAt.UTC(),
+		Duration:        metadata.Duration,
+		Definition:      metadata.Definition,
+		Caption:         metadata.Caption,
+		LicensedContent: metadata.LicensedContent,
+		ViewCount:       viewCount,
+		LikeCount:       likeCount,
+		CommentCount:    commentCount,
+	}, nil
+}
+
+func newPlaylistResource(playlist *youtube.ChannelPlaylist) *PlaylistResource {
+	return &PlaylistResource{
+		Id:         playlist.Id,
+		Title:      playlist.Title,
+		Thumbnail:  playlist.Thumbnail,
+		VideoCount: playlist.VideoCount,
+	}
+}
+
+func (server *Server) getChannelResource(
+	w http.ResponseWriter, req *http.Request, channelId string,
+) (*ChannelResource, bool) {
+	channel, err := server.youtubeFor(req).GetChannel(channelId)
+	if err != nil {
+		writeError(w, req, err, "fetching channel")
+		return nil, false
+	}
+
+	resource, err := newChannelResource(channel)
+	if err != nil {
+		writeError(w, req, err, "reading channel")
+		return nil, false
+	}
+
+	return resource, true
+}
+
+func (server *Server) GetChannelV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channel, ok := server.getChannelResource(w, req, mux.Vars(req)["id"])
+	if !ok {
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, ChannelResponse{Channel: channel})
+}
+
+func (server *Server) GetVideoV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	metadata, err := server.youtubeFor(req).GetVideoMetadata(mux.Vars(req)["id"])
+	if err != nil {
+		writeError(w, req, err, "fetching video metadata")
+		return
+	}
+
+	channel, err := newChannelResource(metadataChannel(metadata))
+	if err != nil {
+		writeError(w, req, err, "reading channel")
+		return
+	}
+
+	video, err := newVideoResource(metadata)
+	if err != nil {
+		writeError(w, req, err, "reading video metadata")
+		return
+	}
+
+	writeJson(w, req, http.StatusOK, VideoResponse{Channel: channel, Video: video})
+}
+
+func (server *Server) GetNeighborsV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	vars := mux.Vars(req)
+
+	options, err := parseChannelVideosOptions(req)
+	if err != nil {
+		writeBadRequest(w, req, err.Error())
+		return
+	}
+
+	videos, err := server.youtubeFor(req).GetChannelVideos(vars["id"], vars["videoId"], options)
+	if err != nil {
+		writeError(w, req, err, "fetching videos")
+		return
+	}
+
+	var channel *ChannelResource
+	if len(videos.Videos) > 0 {
+		channel, err = newChannelResource(metadataChannel(videos.Videos[0]))
+		if err != nil {
+			writeError(w, req, err, "reading channel")
+			return
+		}
+	} else {
+		var ok bool
+		channel, ok = server.getChannelResource(w, req, vars["id"])
+		if !ok {
+			return
+		}
+	}
+
+	resources := make([]*VideoResource, 0, len(videos.Videos))
+	for _, metadata := range videos.Videos {
+		video, err := newVideoResource(metadata)
+		if err != nil {
+			writeError(w, req, err, "reading video metadata")
+			return
+		}
+		resources = append(resources, video)
+	}
+
+	writeJson(w, req, http.StatusOK, NeighborsResponse{
+		Channel:        channel,
+		Position:       videos.Position,
+		TotalResults:   videos.TotalResults,
+		Count:          len(resources),
+		PreviousCursor: videos.PreviousAnchor,
+		NextCursor:     videos.NextAnchor,
+		Videos:         resources,
+	})
+}
+
+func (server *Server) GetChannelPlaylistsV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	channelId := mux.Vars(req)["id"]
+
+	channel, ok := server.getChannelResource(w, req, channelId)
+	if !ok {
+		return
+	}
+
+	playlists, err := server.youtubeFor(req).GetChannelPlaylists(
+		channelId, req.URL.Query().Get("pageToken"),
+	)
+	if err != nil {
+		writeError(w, req, err, "fetching playlists")
+		return
+	}
+
+	resources := make([]*PlaylistResource, 0, len(playlists.Playlists))
+	for _, playlist := range playlists.Playlists {
+		resources = append(resources, newPlaylistResource(playlist))
+	}
+
+	writeJson(w, req, http.StatusOK, PlaylistsResponse{
+		Channel:       channel,
+		TotalResults:  playlists.TotalResults,
+		Count:         len(resources),
+		NextPageToken: playlists.NextPageToken,
+		PrevPageToken: playlists.PrevPageToken,
+		Playlists:     resources,
+	})
+}
+
+func (server *Server) GetVideoPlaylistsV2(w http.ResponseWriter, req *http.Request) {
+	setJsonContentType(w)
+
+	vars := mux.Vars(req)
+
+	channel, ok := server.getChannelResource(w, req, vars["id"])
+	if !ok {
+		return
+	}
+
+	playlists, err := server.youtubeFor(req).GetVideoPlaylists(vars["id"], vars["videoId"])
+	if err != nil {
+		writeError(w, req, err, "looking up playlists")
+		return
+	}
+
+	resources := make([]*PlaylistMembershipResource, 0, len(playlists.Playlists))
+	for _, membership := range playlists.Playlists {
+		resources = append(resources, &PlaylistMembershipResource{
+			Playlist: *newPlaylistResource(membership.Playlist),
+			Position: membership.Position,
+		})
+	}
+
+	writeJson(w, req, http.StatusOK, VideoPlaylistsResponse{
+		Channel:   channel,
+		VideoId:   playlists.VideoId,
+		Count:     len(resources),
+		Playlists: resources,
+	})
+}
+
+func setDeprecationHeaders(w http.ResponseWriter, successor string) {
+	w.Header().Set("Deprecation", "true")
+	if successor != "" {
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+	}
+}
+
+func metadataSuccessor(req *http.Request) string {
+	id, err := youtube.ParseVideoId(req.URL.Query().Get("idorurl"))
+	if err != nil || id == "" {
+		return ""
+	}
+	return "/v2/videos/" + url.PathEscape(id)
+}
+
+func videosSuccessor(req *http.Request) string {
+	channelId := req.URL.Query().Get("channelId")
+	videoId := req.URL.Query().Get("videoId")
+	if channelId == "" || videoId == "" {
+		return ""
+	}
+	return fmt.Sprintf(
+		"/v2/channels/%s/videos/%s/neighbors",
+		url.PathEscape(channelId),
+		url.PathEscape(videoId),
+	)
+}
+
+func pathSuccessor(req *http.Request) string {
+	return "/v2" + req.URL.EscapedPath()
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"yt_search_server/youtube"
+)
+
+func TestChannelResourceHiddenSubscriberCount(t *testing.T) {
+	resource, err := newChannelResource(&youtube.Channel{ChannelId: "UCchannel", VideoCount: "12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.SubscriberCount != nil {
+		t.Errorf("SubscriberCount = %d, want nil", *resource.SubscriberCount)
+	}
+
+	encoded, err := json.Marshal(resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal(encoded, &decoded)
+	if value, ok := decoded["subscriberCount"]; !ok || value != nil {
+		t.Errorf("subscriberCount = %v, want null", value)
+	}
+}
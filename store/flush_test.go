@@ -0,0 +1,144 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fill records samples snapshots of each of videos videos, six hours apart.
+func fill(store *Store, videos int, samples int) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	likes, comments := int64(1234), int64(56)
+	for video := 0; video < videos; video++ {
+		for sample := 0; sample < samples; sample++ {
+			store.AddVideoSnapshot(fmt.Sprintf("video%07d", video), VideoSnapshot{
+				Time:         start.Add(time.Duration(sample) * 6 * time.Hour),
+				ViewCount:    int64(100000 + sample),
+				LikeCount:    &likes,
+				CommentCount: &comments,
+			})
+		}
+	}
+}
+
+func TestFlushSkipsCleanStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(store, 2, 2)
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, storeFile)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("unchanged store was written again: %v", err)
+	}
+
+	store.Track("UCchannel")
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("changed store was not written: %s", err)
+	}
+}
+
+func TestFlushCopyIsUnaffectedByChanges(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Track("UCa")
+	store.Track("UCb")
+	fill(store, 3, 8)
+
+	// A flush encodes its copy without the lock, so nothing recorded or
+	// thinned in the meantime may reach into it.
+	data, dirty := store.copyData()
+	if !dirty {
+		t.Fatal("copyData reported a changed store as clean")
+	}
+	before, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.Thin(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	fill(store, 4, 2)
+	store.Untrack("UCa")
+	store.AddClientKey(ClientKey{Id: "a", Hash: "hash-a"})
+
+	after, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("copy changed from\n%s\nto\n%s", before, after)
+	}
+
+	// The changes are flushed next time.
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for video := 0; video < 4; video++ {
+		videoId := fmt.Sprintf("video%07d", video)
+		if got, want := len(reopened.VideoSnapshots(videoId)), len(store.VideoSnapshots(videoId)); got != want {
+			t.Errorf("%s has %d snapshots on disk, %d in memory", videoId, got, want)
+		}
+	}
+	if tracked := reopened.TrackedChannels(); len(tracked) != 1 || tracked[0] != "UCb" {
+		t.Errorf("tracked channels on disk = %v", tracked)
+	}
+}
+
+// BenchmarkFlush rewrites stores of 30 days of samples, six hours apart, as
+// kept by the default HISTORY_RETENTION and SAMPLE_INTERVAL, for a number of
+// tracked videos.
+func BenchmarkFlush(b *testing.B) {
+	for _, videos := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("videos=%d", videos), func(b *testing.B) {
+			dir := b.TempDir()
+			store, err := Open(dir)
+			if err != nil {
+				b.Fatal(err)
+			}
+			fill(store, videos, 120)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				store.mu.Lock()
+				store.dirty = true
+				store.mu.Unlock()
+				if err := store.Flush(); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+
+			info, err := os.Stat(filepath.Join(dir, storeFile))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(info.Size())/(1<<20), "MB")
+		})
+	}
+}
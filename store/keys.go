@@ -0,0 +1,97 @@
+package store
+
+import (
+	"time"
+)
+
+// A ClientKey is a key issued to a client of the server. Only a hash of the
+// key itself is kept.
+type ClientKey struct {
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+	Hash      string     `json:"hash"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	// RateLimit and RateLimitBurst replace the server's limits for this key
+	// when set.
+	RateLimit      float64 `json:"rateLimit,omitempty"`
+	RateLimitBurst int     `json:"rateLimitBurst,omitempty"`
+}
+
+func (key ClientKey) Active(now time.Time) bool {
+	if key.RevokedAt != nil {
+		return false
+	}
+	return key.ExpiresAt == nil || now.Before(*key.ExpiresAt)
+}
+
+// indexClientKeys rebuilds the lookup of keys by hash. keysMu must be held,
+// or the store not yet shared.
+func (store *Store) indexClientKeys() {
+	store.keyHashes = make(map[string]int, len(store.keys))
+	for i, key := range store.keys {
+		store.keyHashes[key.Hash] = i
+	}
+}
+
+func (store *Store) AddClientKey(key ClientKey) {
+	store.keysMu.Lock()
+	defer store.keysMu.Unlock()
+
+	store.keys = append(store.keys, key)
+	store.keyHashes[key.Hash] = len(store.keys) - 1
+	store.keysDirty = true
+}
+
+func (store *Store) ClientKeys() []ClientKey {
+	store.keysMu.RLock()
+	defer store.keysMu.RUnlock()
+
+	return append([]ClientKey{}, store.keys...)
+}
+
+func (store *Store) ClientKeyByHash(hash string) (ClientKey, bool) {
+	store.keysMu.RLock()
+	defer store.keysMu.RUnlock()
+
+	i, ok := store.keyHashes[hash]
+	if !ok {
+		return ClientKey{}, false
+	}
+	return store.keys[i], true
+}
+
+// RevokeClientKey marks the key revoked, keeping it listed. It reports false
+// if no key has the ID or it was already revoked.
+func (store *Store) RevokeClientKey(id string, now time.Time) bool {
+	store.keysMu.Lock()
+	defer store.keysMu.Unlock()
+
+	for i, key := range store.keys {
+		if key.Id == id && key.RevokedAt == nil {
+			store.keys[i].RevokedAt = &now
+			store.keysDirty = true
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveClientKey deletes the key outright, for undoing an AddClientKey whose
+// flush failed. It reports false if no key has the ID.
+func (store *Store) RemoveClientKey(id string) bool {
+	store.keysMu.Lock()
+	defer store.keysMu.Unlock()
+
+	for i, key := range store.keys {
+		if key.Id == id {
+			store.keys = append(store.keys[:i:i], store.keys[i+1:]...)
+			store.indexClientKeys()
+			store.keysDirty = true
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"time"
+)
+
+// thin keeps the last snapshot of each UTC day among those taken before
+// cutoff, and every snapshot since. Snapshots are in the order they were
+// taken.
+func thin[S any](snapshots []S, timeOf func(S) time.Time, cutoff time.Time) []S {
+	// A new slice, as a flush may still be encoding the old one.
+	kept := make([]S, 0, len(snapshots))
+	for i, snapshot := range snapshots {
+		taken := timeOf(snapshot)
+		if taken.Before(cutoff) && i+1 < len(snapshots) {
+			next := timeOf(snapshots[i+1])
+			if next.Before(cutoff) && sameDay(taken, next) {
+				continue
+			}
+		}
+		kept = append(kept, snapshot)
+	}
+	return kept
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	a, b = a.UTC(), b.UTC()
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// Thin reduces the snapshots taken before cutoff to one a day, the last of
+// each day, so the store stops growing with every sample of old history. It
+// returns the number of snapshots dropped.
+func (store *Store) Thin(cutoff time.Time) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	dropped := 0
+
+	for channelId, snapshots := range store.data.Channels {
+		kept := thin(snapshots, func(s ChannelSnapshot) time.Time { return s.Time }, cutoff)
+		dropped += len(snapshots) - len(kept)
+		store.data.Channels[channelId] = kept
+	}
+	for videoId, snapshots := range store.data.Videos {
+		kept := thin(snapshots, func(s VideoSnapshot) time.Time { return s.Time }, cutoff)
+		dropped += len(snapshots) - len(kept)
+		store.data.Videos[videoId] = kept
+	}
+
+	if dropped > 0 {
+		store.dirty = true
+	}
+	return dropped
+}
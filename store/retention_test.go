@@ -0,0 +1,45 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThin(t *testing.T) {
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, 1, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, taken := range []time.Time{
+		day(1, 0), day(1, 6), day(1, 12), // thinned to day(1, 12)
+		day(2, 6),             // alone on its day
+		day(3, 0), day(3, 18), // after the cutoff, kept
+	} {
+		store.AddVideoSnapshot("video", VideoSnapshot{Time: taken})
+		store.AddChannelSnapshot("channel", ChannelSnapshot{Time: taken})
+	}
+
+	if dropped := store.Thin(day(3, 0)); dropped != 4 {
+		t.Errorf("Thin dropped %d snapshots, want 4", dropped)
+	}
+
+	want := []time.Time{day(1, 12), day(2, 6), day(3, 0), day(3, 18)}
+	videos := store.VideoSnapshots("video")
+	channels := store.ChannelSnapshots("channel")
+	if len(videos) != len(want) || len(channels) != len(want) {
+		t.Fatalf("kept %d video and %d channel snapshots, want %d", len(videos), len(channels), len(want))
+	}
+	for i, taken := range want {
+		if !videos[i].Time.Equal(taken) || !channels[i].Time.Equal(taken) {
+			t.Errorf("snapshot %d taken at %s and %s, want %s", i, videos[i].Time, channels[i].Time, taken)
+		}
+	}
+
+	if dropped := store.Thin(day(3, 0)); dropped != 0 {
+		t.Errorf("thinning again dropped %d snapshots", dropped)
+	}
+}
@@ -0,0 +1,282 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const storeFile = "store.json"
+
+// Counts a channel hides are nil in snapshots, rather than recorded as 0.
+
+type ChannelSnapshot struct {
+	Time            time.Time `json:"time"`
+	SubscriberCount *int64    `json:"subscriberCount,omitempty"`
+	ViewCount       int64     `json:"viewCount"`
+	VideoCount      int64     `json:"videoCount"`
+}
+
+type VideoSnapshot struct {
+	Time         time.Time `json:"time"`
+	ViewCount    int64     `json:"viewCount"`
+	LikeCount    *int64    `json:"likeCount,omitempty"`
+	CommentCount *int64    `json:"commentCount,omitempty"`
+}
+
+type storeData struct {
+	TrackedChannels []string                     `json:"trackedChannels"`
+	Channels        map[string][]ChannelSnapshot `json:"channels"`
+	Videos          map[string][]VideoSnapshot   `json:"videos"`
+	ClientKeys      []ClientKey                  `json:"clientKeys"`
+}
+
+type Store struct {
+	dir string
+
+	mu    sync.Mutex
+	data  storeData
+	dirty bool
+
+	// Client keys are checked on every authenticated request, so they have
+	// their own lock rather than waiting on snapshots being recorded. data's
+	// ClientKeys is only filled in while encoding.
+	keysMu    sync.RWMutex
+	keys      []ClientKey
+	keyHashes map[string]int
+	keysDirty bool
+
+	// flushMu serialises flushes, which write the file without holding mu.
+	flushMu sync.Mutex
+}
+
+func Open(dir string) (*Store, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating store directory %s: %s", dir, err)
+	}
+
+	store := &Store{
+		dir:       dir,
+		keys:      []ClientKey{},
+		keyHashes: make(map[string]int),
+		data: storeData{
+			TrackedChannels: []string{},
+			Channels:        make(map[string][]ChannelSnapshot),
+			Videos:          make(map[string][]VideoSnapshot),
+		},
+	}
+
+	contents, err := os.ReadFile(filepath.Join(dir, storeFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading store: %s", err)
+	}
+
+	err = json.Unmarshal(contents, &store.data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding store: %s", err)
+	}
+
+	if store.data.Channels == nil {
+		store.data.Channels = make(map[string][]ChannelSnapshot)
+	}
+	if store.data.Videos == nil {
+		store.data.Videos = make(map[string][]VideoSnapshot)
+	}
+	store.keys = store.data.ClientKeys
+	store.data.ClientKeys = nil
+	store.indexClientKeys()
+
+	return store, nil
+}
+
+func (store *Store) Track(channelId string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, tracked := range store.data.TrackedChannels {
+		if tracked == channelId {
+			return
+		}
+	}
+
+	store.data.TrackedChannels = append(store.data.TrackedChannels, channelId)
+	sort.Strings(store.data.TrackedChannels)
+	store.dirty = true
+}
+
+func (store *Store) Untrack(channelId string) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i, tracked := range store.data.TrackedChannels {
+		if tracked == channelId {
+			store.data.TrackedChannels = append(
+				store.data.TrackedChannels[:i],
+				store.data.TrackedChannels[i+1:]...,
+			)
+			store.dirty = true
+			return true
+		}
+	}
+
+	return false
+}
+
+func (store *Store) TrackedChannels() []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return append([]string{}, store.data.TrackedChannels...)
+}
+
+func (store *Store) AddChannelSnapshot(channelId string, snapshot ChannelSnapshot) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.data.Channels[channelId] = append(store.data.Channels[channelId], snapshot)
+	store.dirty = true
+}
+
+func (store *Store) ChannelSnapshots(channelId string) []ChannelSnapshot {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return append([]ChannelSnapshot{}, store.data.Channels[channelId]...)
+}
+
+func (store *Store) AddVideoSnapshot(videoId string, snapshot VideoSnapshot) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.data.Videos[videoId] = append(store.data.Videos[videoId], snapshot)
+	store.dirty = true
+}
+
+func (store *Store) VideoSnapshots(videoId string) []VideoSnapshot {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return append([]VideoSnapshot{}, store.data.Videos[videoId]...)
+}
+
+// copyData returns a copy of the store's contents and whether they changed
+// since the last flush, marking them clean. The copy is shallow: snapshots
+// are appended after the copied ones or replaced wholesale, never changed in
+// place, so it can be encoded while samples keep being recorded.
+func (store *Store) copyData() (storeData, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.keysMu.Lock()
+	defer store.keysMu.Unlock()
+
+	dirty := store.dirty || store.keysDirty
+	store.dirty, store.keysDirty = false, false
+	if !dirty {
+		return storeData{}, false
+	}
+
+	data := storeData{
+		TrackedChannels: append([]string{}, store.data.TrackedChannels...),
+		Channels:        make(map[string][]ChannelSnapshot, len(store.data.Channels)),
+		Videos:          make(map[string][]VideoSnapshot, len(store.data.Videos)),
+		ClientKeys:      append([]ClientKey{}, store.keys...),
+	}
+	for channelId, snapshots := range store.data.Channels {
+		data.Channels[channelId] = snapshots[:len(snapshots):len(snapshots)]
+	}
+	for videoId, snapshots := range store.data.Videos {
+		data.Videos[videoId] = snapshots[:len(snapshots):len(snapshots)]
+	}
+	return data, true
+}
+
+// encode returns the store's contents if they changed since the last flush.
+func (store *Store) encode() ([]byte, error) {
+	data, dirty := store.copyData()
+	if !dirty {
+		return nil, nil
+	}
+
+	contents, err := json.Marshal(data)
+	if err != nil {
+		store.mu.Lock()
+		store.dirty = true
+		store.mu.Unlock()
+		return nil, fmt.Errorf("error encoding store: %s", err)
+	}
+	return contents, nil
+}
+
+// Flush writes the store to a temporary file and renames it into place, so a
+// crash mid-write never leaves a truncated store behind. The store's locks are
+// held only to copy it; it is encoded and written without them.
+//
+// Every flush rewrites the whole file, so its cost grows with the number of
+// snapshots kept, about 80 bytes each. HISTORY_RETENTION bounds that number;
+// BenchmarkFlush measures the cost at a few sizes.
+func (store *Store) Flush() error {
+	store.flushMu.Lock()
+	defer store.flushMu.Unlock()
+
+	contents, err := store.encode()
+	if contents == nil {
+		return err
+	}
+
+	err = store.write(contents)
+	if err != nil {
+		// Try again on the next flush.
+		store.mu.Lock()
+		store.dirty = true
+		store.mu.Unlock()
+	}
+	return err
+}
+
+func (store *Store) write(contents []byte) error {
+	temp, err := os.CreateTemp(store.dir, storeFile+".*")
+	if err != nil {
+		return fmt.Errorf("error writing store: %s", err)
+	}
+
+	_, err = temp.Write(contents)
+	if err == nil {
+		err = temp.Sync()
+	}
+	closeErr := temp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("error writing store: %s", err)
+	}
+
+	err = os.Rename(temp.Name(), filepath.Join(store.dir, storeFile))
+	if err != nil {
+		os.Remove(temp.Name())
+		return fmt.Errorf("error writing store: %s", err)
+	}
+
+	return nil
+}
+
+// CheckWritable creates and removes a file in the store directory, confirming
+// that the next Flush can succeed.
+func (store *Store) CheckWritable() error {
+	temp, err := os.CreateTemp(store.dir, storeFile+".check.*")
+	if err != nil {
+		return fmt.Errorf("store directory is not writable: %s", err)
+	}
+	temp.Close()
+	return os.Remove(temp.Name())
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClientKeysPersist(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	store.AddClientKey(ClientKey{Id: "a", Hash: "hash-a", CreatedAt: now})
+	store.AddClientKey(ClientKey{Id: "b", Hash: "hash-b", CreatedAt: now})
+	if !store.RevokeClientKey("a", now) {
+		t.Fatal("RevokeClientKey(a) = false")
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := reopened.ClientKeys(); len(keys) != 2 {
+		t.Fatalf("ClientKeys() has %d keys, want 2", len(keys))
+	}
+	key, ok := reopened.ClientKeyByHash("hash-a")
+	if !ok || key.Id != "a" || key.Active(now) {
+		t.Errorf("ClientKeyByHash(hash-a) = %+v, %v; want revoked key a", key, ok)
+	}
+	if key, ok := reopened.ClientKeyByHash("hash-b"); !ok || key.Id != "b" {
+		t.Errorf("ClientKeyByHash(hash-b) = %+v, %v; want key b", key, ok)
+	}
+	if _, ok := reopened.ClientKeyByHash("hash-c"); ok {
+		t.Error("ClientKeyByHash(hash-c) found a key")
+	}
+}
+
+func TestFlushRetriesAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.AddClientKey(ClientKey{Id: "a", Hash: "hash-a"})
+
+	store.dir = dir + "/missing"
+	if err := store.Flush(); err == nil {
+		t.Fatal("Flush into a missing directory succeeded")
+	}
+
+	store.dir = dir
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir + "/" + storeFile); err != nil {
+		t.Fatalf("store was not written after the failed flush: %s", err)
+	}
+}
@@ -0,0 +1,269 @@
+package youtube
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type CadenceBucket struct {
+	Start string `json:"start"`
+	Count int    `json:"count"`
+}
+
+type CadenceVideo struct {
+	VideoId     string    `json:"videoId"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+type Hiatus struct {
+	Seconds int64         `json:"seconds"`
+	Before  *CadenceVideo `json:"before"`
+	After   *CadenceVideo `json:"after"`
+}
+
+type Streak struct {
+	Length int    `json:"length"`
+	Start  string `json:"start,omitempty"`
+	End    string `json:"end,omitempty"`
+}
+
+type Streaks struct {
+	LongestDaily  Streak `json:"longestDaily"`
+	CurrentDaily  Streak `json:"currentDaily"`
+	LongestWeekly Streak `json:"longestWeekly"`
+	CurrentWeekly Streak `json:"currentWeekly"`
+}
+
+type Cadence struct {
+	Timezone              string          `json:"timezone"`
+	UploadCount           int             `json:"uploadCount"`
+	FirstUpload           *time.Time      `json:"firstUpload"`
+	LastUpload            *time.Time      `json:"lastUpload"`
+	MeanIntervalSeconds   float64         `json:"meanIntervalSeconds"`
+	MedianIntervalSeconds float64         `json:"medianIntervalSeconds"`
+	LongestHiatus         *Hiatus         `json:"longestHiatus"`
+	Streaks               Streaks         `json:"streaks"`
+	Daily                 []CadenceBucket `json:"daily"`
+	Weekly                []CadenceBucket `json:"weekly"`
+	Monthly               []CadenceBucket `json:"monthly"`
+	DayOfWeek             []CadenceBucket `json:"dayOfWeek"`
+	HourOfDay             []CadenceBucket `json:"hourOfDay"`
+}
+
+const dateFormat = "2006-01-02"
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Weeks start on Monday, as in ISO 8601.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func histogram(
+	times []time.Time,
+	start func(time.Time) time.Time,
+	next func(time.Time) time.Time,
+	format string,
+) []CadenceBucket {
+	buckets := []CadenceBucket{}
+	if len(times) == 0 {
+		return buckets
+	}
+
+	counts := make(map[time.Time]int)
+	for _, t := range times {
+		counts[start(t)]++
+	}
+
+	last := start(times[len(times)-1])
+	for bucket := start(times[0]); !bucket.After(last); bucket = next(bucket) {
+		buckets = append(buckets, CadenceBucket{
+			Start: bucket.Format(format),
+			Count: counts[bucket],
+		})
+	}
+
+	return buckets
+}
+
+// A current streak is still alive if it reaches the present bucket or the one
+// before it, since the creator may not have uploaded yet today.
+func streaks(
+	buckets []CadenceBucket, present string, previous string,
+) (longest Streak, current Streak) {
+	run := Streak{}
+	for _, bucket := range buckets {
+		if bucket.Count == 0 {
+			run = Streak{}
+			continue
+		}
+		if run.Length == 0 {
+			run.Start = bucket.Start
+		}
+		run.Length++
+		run.End = bucket.Start
+		if run.Length > longest.Length {
+			longest = run
+		}
+	}
+	if run.End != present && run.End != previous {
+		run = Streak{}
+	}
+	return longest, run
+}
+
+func (youtube *YouTube) GetChannelCadence(
+	channelId string, options ChannelVideosOptions, location *time.Location,
+) (*Cadence, error) {
+	options.Descending = false
+
+	videos, err := youtube.GetTimeline(channelId, options)
+	if err != nil {
+		return nil, err
+	}
+
+	cadence, err := computeCadence(videos, location, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if cadence.LongestHiatus == nil {
+		return cadence, nil
+	}
+
+	before, after := cadence.LongestHiatus.Before, cadence.LongestHiatus.After
+	details, err := youtube.GetVideoDetails([]string{before.VideoId, after.VideoId})
+	if err != nil {
+		return nil, err
+	}
+	for _, video := range []*CadenceVideo{before, after} {
+		if videoDetails, ok := details[video.VideoId]; ok {
+			video.Title = videoDetails.Title
+		}
+	}
+
+	return cadence, nil
+}
+
+// computeCadence describes when videos were uploaded, as seen in location at
+// now. The videos of the longest hiatus are left without titles.
+func computeCadence(
+	videos []PlaylistVideo, location *time.Location, now time.Time,
+) (*Cadence, error) {
+	times := make([]time.Time, len(videos))
+	for i, video := range videos {
+		publishedAt, err := parseTimestamp(video.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read video %s: %w", video.VideoId, err)
+		}
+		times[i] = publishedAt.In(location)
+	}
+
+	// The timeline may be ordered by another key; cadence follows upload time.
+	order := make([]int, len(videos))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return times[order[i]].Before(times[order[j]])
+	})
+
+	sortedTimes := make([]time.Time, len(times))
+	for i, j := range order {
+		sortedTimes[i] = times[j]
+	}
+
+	cadence := &Cadence{
+		Timezone:    location.String(),
+		UploadCount: len(videos),
+		Daily: histogram(sortedTimes, startOfDay, func(t time.Time) time.Time {
+			return t.AddDate(0, 0, 1)
+		}, dateFormat),
+		Weekly: histogram(sortedTimes, startOfWeek, func(t time.Time) time.Time {
+			return t.AddDate(0, 0, 7)
+		}, dateFormat),
+		Monthly: histogram(sortedTimes, startOfMonth, func(t time.Time) time.Time {
+			return t.AddDate(0, 1, 0)
+		}, "2006-01"),
+		DayOfWeek: make([]CadenceBucket, 7),
+		HourOfDay: make([]CadenceBucket, 24),
+	}
+
+	for day := range cadence.DayOfWeek {
+		cadence.DayOfWeek[day].Start = time.Weekday((day + 1) % 7).String()
+	}
+	for hour := range cadence.HourOfDay {
+		cadence.HourOfDay[hour].Start = fmt.Sprintf("%02d:00", hour)
+	}
+	for _, t := range sortedTimes {
+		cadence.DayOfWeek[(int(t.Weekday())+6)%7].Count++
+		cadence.HourOfDay[t.Hour()].Count++
+	}
+
+	now = now.In(location)
+	today := startOfDay(now)
+	thisWeek := startOfWeek(now)
+
+	cadence.Streaks.LongestDaily, cadence.Streaks.CurrentDaily = streaks(
+		cadence.Daily,
+		today.Format(dateFormat),
+		today.AddDate(0, 0, -1).Format(dateFormat),
+	)
+	cadence.Streaks.LongestWeekly, cadence.Streaks.CurrentWeekly = streaks(
+		cadence.Weekly,
+		thisWeek.Format(dateFormat),
+		thisWeek.AddDate(0, 0, -7).Format(dateFormat),
+	)
+
+	if len(sortedTimes) == 0 {
+		return cadence, nil
+	}
+
+	cadence.FirstUpload = &sortedTimes[0]
+	cadence.LastUpload = &sortedTimes[len(sortedTimes)-1]
+
+	if len(sortedTimes) < 2 {
+		return cadence, nil
+	}
+
+	intervals := make([]float64, len(sortedTimes)-1)
+	total := 0.0
+	longest := 0
+	for i := 1; i < len(sortedTimes); i++ {
+		intervals[i-1] = sortedTimes[i].Sub(sortedTimes[i-1]).Seconds()
+		total += intervals[i-1]
+		if intervals[i-1] > intervals[longest] {
+			longest = i - 1
+		}
+	}
+
+	cadence.MeanIntervalSeconds = total / float64(len(intervals))
+
+	sortedIntervals := append([]float64{}, intervals...)
+	sort.Float64s(sortedIntervals)
+	middle := len(sortedIntervals) / 2
+	if len(sortedIntervals)%2 == 0 {
+		cadence.MedianIntervalSeconds = (sortedIntervals[middle-1] + sortedIntervals[middle]) / 2
+	} else {
+		cadence.MedianIntervalSeconds = sortedIntervals[middle]
+	}
+
+	before := videos[order[longest]]
+	after := videos[order[longest+1]]
+	cadence.LongestHiatus = &Hiatus{
+		Seconds: int64(intervals[longest]),
+		Before:  &CadenceVideo{VideoId: before.VideoId, PublishedAt: sortedTimes[longest]},
+		After:   &CadenceVideo{VideoId: after.VideoId, PublishedAt: sortedTimes[longest+1]},
+	}
+
+	return cadence, nil
+}
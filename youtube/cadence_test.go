@@ -0,0 +1,220 @@
+package youtube
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// uploads is a timeline of videos named after their position, published at
+// the given RFC 3339 timestamps.
+func uploads(timestamps ...string) []PlaylistVideo {
+	videos := make([]PlaylistVideo, len(timestamps))
+	for i, timestamp := range timestamps {
+		videos[i] = PlaylistVideo{VideoId: fmt.Sprintf("v%d", i), PublishedAt: timestamp}
+	}
+	return videos
+}
+
+// counts lists the counts of buckets, or their starts and counts with
+// starts.
+func counts(buckets []CadenceBucket, starts bool) []string {
+	got := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		got[i] = fmt.Sprint(bucket.Count)
+		if starts {
+			got[i] = fmt.Sprintf("%s=%d", bucket.Start, bucket.Count)
+		}
+	}
+	return got
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func TestComputeCadence(t *testing.T) {
+	// Out of order, as a timeline sorted by another key would be. The third
+	// upload is late on a Wednesday in UTC but early on Thursday in Berlin.
+	videos := uploads(
+		"2024-03-20T12:00:00Z",
+		"2024-03-04T10:00:00Z",
+		"2024-03-06T23:30:00Z",
+		"2024-03-05T10:00:00Z",
+	)
+	now := time.Date(2024, 3, 21, 9, 0, 0, 0, time.UTC)
+
+	t.Run("UTC", func(t *testing.T) {
+		cadence, err := computeCadence(videos, time.UTC, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cadence.Timezone != "UTC" || cadence.UploadCount != 4 {
+			t.Errorf("timezone %s, upload count %d", cadence.Timezone, cadence.UploadCount)
+		}
+		if !cadence.FirstUpload.Equal(time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)) ||
+			!cadence.LastUpload.Equal(time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("first %s, last %s", cadence.FirstUpload, cadence.LastUpload)
+		}
+
+		// Intervals of 24h, 37.5h and 13d 12.5h.
+		if cadence.MeanIntervalSeconds != 463200 || cadence.MedianIntervalSeconds != 135000 {
+			t.Errorf("mean %v, median %v", cadence.MeanIntervalSeconds, cadence.MedianIntervalSeconds)
+		}
+		hiatus := cadence.LongestHiatus
+		if hiatus.Seconds != 1168200 || hiatus.Before.VideoId != "v2" || hiatus.After.VideoId != "v0" {
+			t.Errorf("hiatus %d s from %s to %s", hiatus.Seconds, hiatus.Before.VideoId, hiatus.After.VideoId)
+		}
+
+		wantDays := []string{
+			"Monday=1", "Tuesday=1", "Wednesday=2", "Thursday=0",
+			"Friday=0", "Saturday=0", "Sunday=0",
+		}
+		if got := counts(cadence.DayOfWeek, true); !reflect.DeepEqual(got, wantDays) {
+			t.Errorf("day of week = %v, want %v", got, wantDays)
+		}
+		hours := counts(cadence.HourOfDay, false)
+		if hours[10] != "2" || hours[12] != "1" || hours[23] != "1" || cadence.HourOfDay[23].Start != "23:00" {
+			t.Errorf("hour of day = %v", hours)
+		}
+
+		if len(cadence.Daily) != 17 || cadence.Daily[0].Start != "2024-03-04" {
+			t.Errorf("daily = %v", cadence.Daily)
+		}
+		wantWeeks := []string{"2024-03-04=3", "2024-03-11=0", "2024-03-18=1"}
+		if got := counts(cadence.Weekly, true); !reflect.DeepEqual(got, wantWeeks) {
+			t.Errorf("weekly = %v, want %v", got, wantWeeks)
+		}
+		if got := counts(cadence.Monthly, true); !reflect.DeepEqual(got, []string{"2024-03=4"}) {
+			t.Errorf("monthly = %v", got)
+		}
+
+		want := Streaks{
+			LongestDaily:  Streak{Length: 3, Start: "2024-03-04", End: "2024-03-06"},
+			CurrentDaily:  Streak{Length: 1, Start: "2024-03-20", End: "2024-03-20"},
+			LongestWeekly: Streak{Length: 1, Start: "2024-03-04", End: "2024-03-04"},
+			CurrentWeekly: Streak{Length: 1, Start: "2024-03-18", End: "2024-03-18"},
+		}
+		if cadence.Streaks != want {
+			t.Errorf("streaks = %+v, want %+v", cadence.Streaks, want)
+		}
+	})
+
+	t.Run("Berlin", func(t *testing.T) {
+		cadence, err := computeCadence(videos, mustLoadLocation(t, "Europe/Berlin"), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cadence.Timezone != "Europe/Berlin" || cadence.FirstUpload.Hour() != 11 {
+			t.Errorf("timezone %s, first upload %s", cadence.Timezone, cadence.FirstUpload)
+		}
+		// Time zones move uploads between days but leave intervals alone.
+		if cadence.MeanIntervalSeconds != 463200 || cadence.LongestHiatus.Seconds != 1168200 {
+			t.Errorf("mean %v, hiatus %d", cadence.MeanIntervalSeconds, cadence.LongestHiatus.Seconds)
+		}
+
+		wantDays := []string{"1", "1", "1", "1", "0", "0", "0"}
+		if got := counts(cadence.DayOfWeek, false); !reflect.DeepEqual(got, wantDays) {
+			t.Errorf("day of week = %v, want %v", got, wantDays)
+		}
+		hours := counts(cadence.HourOfDay, false)
+		if hours[0] != "1" || hours[11] != "2" || hours[13] != "1" {
+			t.Errorf("hour of day = %v", hours)
+		}
+
+		want := Streak{Length: 2, Start: "2024-03-04", End: "2024-03-05"}
+		if cadence.Streaks.LongestDaily != want {
+			t.Errorf("longest daily streak = %+v, want %+v", cadence.Streaks.LongestDaily, want)
+		}
+	})
+
+	t.Run("lapsed streaks", func(t *testing.T) {
+		later := time.Date(2024, 4, 10, 9, 0, 0, 0, time.UTC)
+		cadence, err := computeCadence(videos, time.UTC, later)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cadence.Streaks.CurrentDaily != (Streak{}) || cadence.Streaks.CurrentWeekly != (Streak{}) {
+			t.Errorf("current streaks = %+v", cadence.Streaks)
+		}
+		if cadence.Streaks.LongestDaily.Length != 3 {
+			t.Errorf("longest daily streak = %+v", cadence.Streaks.LongestDaily)
+		}
+	})
+
+	t.Run("invalid timestamp", func(t *testing.T) {
+		if _, err := computeCadence(uploads("2024-03-04 10:00"), time.UTC, now); err == nil {
+			t.Error("computeCadence succeeded")
+		}
+	})
+}
+
+func TestComputeCadenceFewUploads(t *testing.T) {
+	now := time.Date(2024, 3, 21, 9, 0, 0, 0, time.UTC)
+
+	t.Run("none", func(t *testing.T) {
+		cadence, err := computeCadence(uploads(), time.UTC, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cadence.UploadCount != 0 || cadence.FirstUpload != nil || cadence.LastUpload != nil {
+			t.Errorf("cadence = %+v", cadence)
+		}
+		if cadence.LongestHiatus != nil || cadence.MeanIntervalSeconds != 0 || cadence.MedianIntervalSeconds != 0 {
+			t.Errorf("intervals of no uploads = %+v", cadence)
+		}
+		if len(cadence.Daily) != 0 || len(cadence.Weekly) != 0 || len(cadence.Monthly) != 0 {
+			t.Errorf("histograms of no uploads = %v, %v, %v", cadence.Daily, cadence.Weekly, cadence.Monthly)
+		}
+		if len(cadence.DayOfWeek) != 7 || len(cadence.HourOfDay) != 24 {
+			t.Errorf("%d weekdays, %d hours", len(cadence.DayOfWeek), len(cadence.HourOfDay))
+		}
+		if cadence.Streaks != (Streaks{}) {
+			t.Errorf("streaks = %+v", cadence.Streaks)
+		}
+	})
+
+	t.Run("one", func(t *testing.T) {
+		cadence, err := computeCadence(uploads("2024-03-21T08:00:00Z"), time.UTC, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cadence.UploadCount != 1 || !cadence.FirstUpload.Equal(*cadence.LastUpload) {
+			t.Errorf("first %s, last %s", cadence.FirstUpload, cadence.LastUpload)
+		}
+		if cadence.LongestHiatus != nil || cadence.MeanIntervalSeconds != 0 {
+			t.Errorf("hiatus %+v, mean %v", cadence.LongestHiatus, cadence.MeanIntervalSeconds)
+		}
+		want := Streak{Length: 1, Start: "2024-03-21", End: "2024-03-21"}
+		if cadence.Streaks.CurrentDaily != want || cadence.Streaks.LongestDaily != want {
+			t.Errorf("daily streaks = %+v", cadence.Streaks)
+		}
+	})
+
+	t.Run("two", func(t *testing.T) {
+		cadence, err := computeCadence(
+			uploads("2024-03-10T08:00:00Z", "2024-03-01T08:00:00Z"), time.UTC, now,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		const interval = 9 * 24 * 60 * 60
+		if cadence.MeanIntervalSeconds != interval || cadence.MedianIntervalSeconds != interval {
+			t.Errorf("mean %v, median %v", cadence.MeanIntervalSeconds, cadence.MedianIntervalSeconds)
+		}
+		hiatus := cadence.LongestHiatus
+		if hiatus.Seconds != interval || hiatus.Before.VideoId != "v1" || hiatus.After.VideoId != "v0" {
+			t.Errorf("hiatus %d s from %s to %s", hiatus.Seconds, hiatus.Before.VideoId, hiatus.After.VideoId)
+		}
+		if cadence.Streaks.CurrentDaily != (Streak{}) || cadence.Streaks.LongestDaily.Length != 1 {
+			t.Errorf("daily streaks = %+v", cadence.Streaks)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package youtube
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const MaxPredictionWeeks = 12
+
+const predictionLookbackWeeks = 26
+
+type CalendarUpload struct {
+	VideoId     string
+	Title       string
+	Description string
+	PublishedAt time.Time
+	Duration    int
+	Livestream  bool
+}
+
+type UploadSlot struct {
+	Weekday time.Weekday
+	Hour    int
+	Count   int
+}
+
+type PredictedUpload struct {
+	Start time.Time
+	Slot  UploadSlot
+	// Share is the fraction of recent uploads that fell into the slot.
+	Share float64
+}
+
+// GetCalendarUploads returns the uploads among videos, a channel's timeline,
+// published in [from, to), oldest first, with their details. A zero from or
+// to leaves that end of the range open.
+func (youtube *YouTube) GetCalendarUploads(
+	videos []PlaylistVideo, from time.Time, to time.Time,
+) ([]*CalendarUpload, error) {
+	selected := []PlaylistVideo{}
+	times := []time.Time{}
+	for _, video := range videos {
+		publishedAt, err := parseTimestamp(video.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read video %s: %w", video.VideoId, err)
+		}
+		if !from.IsZero() && publishedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !publishedAt.Before(to) {
+			continue
+		}
+		selected = append(selected, video)
+		times = append(times, publishedAt)
+	}
+
+	videoIds := make([]string, len(selected))
+	for i, video := range selected {
+		videoIds[i] = video.VideoId
+	}
+
+	details, err := youtube.GetVideoDetails(videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := make([]*CalendarUpload, 0, len(selected))
+	for i, video := range selected {
+		upload := &CalendarUpload{
+			VideoId:     video.VideoId,
+			PublishedAt: times[i],
+		}
+		if videoDetails, ok := details[video.VideoId]; ok {
+			upload.Title = videoDetails.Title
+			upload.Description = videoDetails.Description
+			upload.Duration = videoDetails.Duration
+			upload.Livestream = videoDetails.Livestream
+		}
+		uploads = append(uploads, upload)
+	}
+
+	sort.SliceStable(uploads, func(i, j int) bool {
+		return uploads[i].PublishedAt.Before(uploads[j].PublishedAt)
+	})
+
+	return uploads, nil
+}
+
+// PredictUploads projects the recent cadence of videos, a channel's timeline,
+// forward. Uploads from the last 26 weeks are bucketed by weekday and hour in
+// location, and the busiest buckets, as many as the channel's average weekly
+// upload count, are repeated for each of the next weeks.
+func PredictUploads(
+	videos []PlaylistVideo,
+	location *time.Location,
+	now time.Time,
+	weeks int,
+) ([]*PredictedUpload, error) {
+	if weeks < 0 || weeks > MaxPredictionWeeks {
+		return nil, invalidf(
+			"prediction must cover between 0 and %d weeks", MaxPredictionWeeks,
+		)
+	}
+
+	predictions := []*PredictedUpload{}
+	if weeks == 0 {
+		return predictions, nil
+	}
+
+	since := now.AddDate(0, 0, -7*predictionLookbackWeeks)
+
+	counts := make(map[[2]int]int)
+	total := 0
+	for _, video := range videos {
+		publishedAt, err := parseTimestamp(video.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read video %s: %w", video.VideoId, err)
+		}
+		if publishedAt.Before(since) || publishedAt.After(now) {
+			continue
+		}
+		local := publishedAt.In(location)
+		counts[[2]int{int(local.Weekday()), local.Hour()}]++
+		total++
+	}
+
+	if total == 0 {
+		return predictions, nil
+	}
+
+	slots := make([]UploadSlot, 0, len(counts))
+	for key, count := range counts {
+		slots = append(slots, UploadSlot{
+			Weekday: time.Weekday(key[0]),
+			Hour:    key[1],
+			Count:   count,
+		})
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].Count != slots[j].Count {
+			return slots[i].Count > slots[j].Count
+		}
+		if slots[i].Weekday != slots[j].Weekday {
+			return slots[i].Weekday < slots[j].Weekday
+		}
+		return slots[i].Hour < slots[j].Hour
+	})
+
+	perWeek := int(math.Round(float64(total) / predictionLookbackWeeks))
+	if perWeek < 1 {
+		perWeek = 1
+	}
+	if perWeek < len(slots) {
+		slots = slots[:perWeek]
+	}
+
+	today := startOfDay(now.In(location))
+	end := today.AddDate(0, 0, 7*weeks+1)
+
+	for day := today; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for _, slot := range slots {
+			if day.Weekday() != slot.Weekday {
+				continue
+			}
+			start := time.Date(
+				day.Year(), day.Month(), day.Day(), slot.Hour, 0, 0, 0, location,
+			)
+			if !start.After(now) || !start.Before(now.AddDate(0, 0, 7*weeks)) {
+				continue
+			}
+			predictions = append(predictions, &PredictedUpload{
+				Start: start,
+				Slot:  slot,
+				Share: float64(slot.Count) / float64(total),
+			})
+		}
+	}
+
+	sort.SliceStable(predictions, func(i, j int) bool {
+		return predictions[i].Start.Before(predictions[j].Start)
+	})
+
+	return predictions, nil
+}
@@ -0,0 +1,43 @@
+package youtube
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPredictUploads(t *testing.T) {
+	// Every Monday at 15:00 UTC for 26 weeks, so one upload a week.
+	now := time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC) // a Wednesday
+	videos := []PlaylistVideo{}
+	for week := 1; week <= predictionLookbackWeeks; week++ {
+		published := time.Date(2024, 7, 1, 15, 0, 0, 0, time.UTC).AddDate(0, 0, -7*(week-1))
+		videos = append(videos, PlaylistVideo{
+			VideoId:     fmt.Sprintf("video%d", week),
+			PublishedAt: published.Format(time.RFC3339),
+		})
+	}
+
+	predictions, err := PredictUploads(videos, time.UTC, now, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []time.Time{
+		time.Date(2024, 7, 8, 15, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 15, 15, 0, 0, 0, time.UTC),
+	}
+	if len(predictions) != len(want) {
+		t.Fatalf("got %d predictions, want %d", len(predictions), len(want))
+	}
+	for i, prediction := range predictions {
+		if !prediction.Start.Equal(want[i]) || prediction.Share != 1 {
+			t.Errorf("prediction %d = %s with share %g, want %s with share 1",
+				i, prediction.Start, prediction.Share, want[i])
+		}
+	}
+
+	if _, err := PredictUploads(videos, time.UTC, now, MaxPredictionWeeks+1); err == nil {
+		t.Errorf("PredictUploads allowed %d weeks", MaxPredictionWeeks+1)
+	}
+}
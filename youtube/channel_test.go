@@ -0,0 +1,53 @@
+package youtube
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// channelResponse is a channels response whose statistics hold the given
+// extra fields.
+func channelResponse(statistics string) string {
+	return fmt.Sprintf(`{"items": [{
+		"snippet": {
+			"title": "Channel",
+			"customUrl": "@channel",
+			"thumbnails": {"medium": {"url": "https://example.com/thumb.jpg"}}
+		},
+		"statistics": {"videoCount": "12", "viewCount": "3400"%s}
+	}]}`, statistics)
+}
+
+func TestGetChannelHiddenSubscriberCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		statistics string
+		want       string
+	}{
+		{name: "shown", statistics: `, "subscriberCount": "560", "hiddenSubscriberCount": false`, want: "560"},
+		{name: "left out", statistics: `, "hiddenSubscriberCount": true`, want: ""},
+		{name: "no flag or count", statistics: ``, want: ""},
+		{name: "hidden but given", statistics: `, "subscriberCount": "100", "hiddenSubscriberCount": true`, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTransport(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, http.StatusOK, channelResponse(test.statistics)), nil
+			})
+
+			youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+			channel, err := youtube.GetChannel("UCchannel")
+			if err != nil {
+				t.Fatalf("GetChannel error = %s", err)
+			}
+			if channel.SubscriberCount != test.want {
+				t.Errorf("SubscriberCount = %q, want %q", channel.SubscriberCount, test.want)
+			}
+			if channel.VideoCount != "12" || channel.ViewCount != "3400" {
+				t.Errorf("counts = %q videos, %q views", channel.VideoCount, channel.ViewCount)
+			}
+		})
+	}
+}
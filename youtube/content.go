@@ -0,0 +1,62 @@
+package youtube
+
+import (
+	"strings"
+)
+
+type ContentType string
+
+const (
+	ContentTypeAll    ContentType = "all"
+	ContentTypeVideos ContentType = "videos"
+	ContentTypeShorts ContentType = "shorts"
+	ContentTypeLive   ContentType = "live"
+)
+
+// Shorts can be up to three minutes long, so anything longer is long-form.
+const ShortsMaxDuration = 180
+
+var contentTypePlaylistPrefixes = map[ContentType]string{
+	ContentTypeAll:    "UU",
+	ContentTypeVideos: "UULF",
+	ContentTypeShorts: "UUSH",
+	ContentTypeLive:   "UULV",
+}
+
+func ParseContentType(value string) (ContentType, error) {
+	if value == "" {
+		return ContentTypeAll, nil
+	}
+
+	contentType := ContentType(strings.ToLower(value))
+	if _, ok := contentTypePlaylistPrefixes[contentType]; !ok {
+		return "", invalidf(
+			"unknown content type %s. Expected one of all, videos, shorts, live",
+			value,
+		)
+	}
+
+	return contentType, nil
+}
+
+func (contentType ContentType) playlistId(uploadsPlaylistId string) string {
+	if contentType == "" || !strings.HasPrefix(uploadsPlaylistId, "UU") {
+		return uploadsPlaylistId
+	}
+
+	return contentTypePlaylistPrefixes[contentType] +
+		strings.TrimPrefix(uploadsPlaylistId, "UU")
+}
+
+func (contentType ContentType) matches(details *VideoDetails) bool {
+	switch contentType {
+	case ContentTypeLive:
+		return details.Livestream
+	case ContentTypeShorts:
+		return !details.Livestream && details.Duration <= ShortsMaxDuration
+	case ContentTypeVideos:
+		return !details.Livestream && details.Duration > ShortsMaxDuration
+	default:
+		return true
+	}
+}
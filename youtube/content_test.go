@@ -0,0 +1,77 @@
+package youtube
+
+import "testing"
+
+func TestContentTypePlaylistId(t *testing.T) {
+	tests := []struct {
+		contentType ContentType
+		uploads     string
+		want        string
+	}{
+		{contentType: ContentTypeAll, uploads: "UUabc", want: "UUabc"},
+		{contentType: ContentTypeVideos, uploads: "UUabc", want: "UULFabc"},
+		{contentType: ContentTypeShorts, uploads: "UUabc", want: "UUSHabc"},
+		{contentType: ContentTypeLive, uploads: "UUabc", want: "UULVabc"},
+		{contentType: "", uploads: "UUabc", want: "UUabc"},
+		// Playlists that are not uploads playlists are left alone.
+		{contentType: ContentTypeShorts, uploads: "PLabc", want: "PLabc"},
+	}
+
+	for _, test := range tests {
+		if got := test.contentType.playlistId(test.uploads); got != test.want {
+			t.Errorf("%q.playlistId(%q) = %q, want %q", test.contentType, test.uploads, got, test.want)
+		}
+	}
+}
+
+func TestContentTypeMatches(t *testing.T) {
+	videos := map[string]*VideoDetails{
+		"short":      {Duration: 59},
+		"threeMin":   {Duration: ShortsMaxDuration},
+		"longForm":   {Duration: ShortsMaxDuration + 1},
+		"shortLive":  {Duration: 30, Livestream: true},
+		"longLive":   {Duration: 7200, Livestream: true},
+		"notStarted": {Duration: 0, Livestream: true},
+	}
+
+	tests := []struct {
+		contentType ContentType
+		want        []string
+	}{
+		{contentType: ContentTypeAll, want: []string{"short", "threeMin", "longForm", "shortLive", "longLive", "notStarted"}},
+		{contentType: "", want: []string{"short", "threeMin", "longForm", "shortLive", "longLive", "notStarted"}},
+		{contentType: ContentTypeShorts, want: []string{"short", "threeMin"}},
+		{contentType: ContentTypeVideos, want: []string{"longForm"}},
+		{contentType: ContentTypeLive, want: []string{"shortLive", "longLive", "notStarted"}},
+	}
+
+	for _, test := range tests {
+		want := make(map[string]bool)
+		for _, name := range test.want {
+			want[name] = true
+		}
+		for name, details := range videos {
+			if got := test.contentType.matches(details); got != want[name] {
+				t.Errorf("%q.matches(%s) = %v, want %v", test.contentType, name, got, want[name])
+			}
+		}
+	}
+}
+
+func TestParseContentType(t *testing.T) {
+	for value, want := range map[string]ContentType{
+		"":       ContentTypeAll,
+		"all":    ContentTypeAll,
+		"Shorts": ContentTypeShorts,
+		"LIVE":   ContentTypeLive,
+		"videos": ContentTypeVideos,
+	} {
+		if got, err := ParseContentType(value); err != nil || got != want {
+			t.Errorf("ParseContentType(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+
+	if _, err := ParseContentType("podcasts"); err == nil {
+		t.Error(`ParseContentType("podcasts") succeeded`)
+	}
+}
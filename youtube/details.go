@@ -0,0 +1,346 @@
+package youtube
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const VideoDetailsTTL = 6 * time.Hour
+
+const videoDetailsBatchSize = 50
+
+const videoDetailsWorkers = 5
+
+type VideoDetails struct {
+	VideoId         string
+	Title           string
+	Description     string
+	Tags            []string
+	Thumbnail       string
+	PublishedAt     string
+	ActualStartTime string
+	RecordingDate   string
+	Duration        int
+	Definition      string
+	Caption         bool
+	LicensedContent bool
+	Livestream      bool
+	ViewCount       string
+	LikeCount       string
+	CommentCount    string
+
+	fetchedAt time.Time
+}
+
+func (youtube *YouTube) GetVideoDetails(
+	videoIds []string,
+) (map[string]*VideoDetails, error) {
+	details := make(map[string]*VideoDetails, len(videoIds))
+	missing := []string{}
+
+	youtube.videoDetailsMu.Lock()
+	for _, videoId := range videoIds {
+		cached, ok := youtube.videoDetails[videoId]
+		hit := ok && time.Since(cached.fetchedAt) < youtube.videoDetailsTTL
+		countCacheLookup("videoDetails", hit)
+		if hit {
+			details[videoId] = cached
+		} else {
+			missing = append(missing, videoId)
+		}
+	}
+	youtube.videoDetailsMu.Unlock()
+
+	batches := [][]string{}
+	for start := 0; start < len(missing); start += videoDetailsBatchSize {
+		end := start + videoDetailsBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		batches = append(batches, missing[start:end])
+	}
+
+	chBatches := make(chan []string)
+	chErrors := make(chan error, len(batches))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < videoDetailsWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range chBatches {
+				fetched, err := youtube.fetchVideoDetails(batch)
+				if err != nil {
+					chErrors <- err
+					continue
+				}
+				mu.Lock()
+				for videoId, video := range fetched {
+					details[videoId] = video
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, batch := range batches {
+		chBatches <- batch
+	}
+	close(chBatches)
+	wg.Wait()
+	close(chErrors)
+
+	if err, ok := <-chErrors; ok {
+		return nil, err
+	}
+
+	youtube.videoDetailsMu.Lock()
+	for _, videoId := range missing {
+		if video, ok := details[videoId]; ok {
+			youtube.videoDetails[videoId] = video
+		}
+	}
+	youtube.videoDetailsMu.Unlock()
+
+	return details, nil
+}
+
+// ForEachVideoDetails fetches details one batch at a time and hands them to fn
+// with their index in videoIds, in order, without adding them to the cache, so
+// arbitrarily long lists can be streamed. Videos the API no longer returns are
+// skipped.
+func (youtube *YouTube) ForEachVideoDetails(
+	videoIds []string, fn func(int, *VideoDetails) error,
+) error {
+	for start := 0; start < len(videoIds); start += videoDetailsBatchSize {
+		end := start + videoDetailsBatchSize
+		if end > len(videoIds) {
+			end = len(videoIds)
+		}
+		batch := videoIds[start:end]
+
+		details := make(map[string]*VideoDetails, len(batch))
+		missing := []string{}
+
+		youtube.videoDetailsMu.Lock()
+		for _, videoId := range batch {
+			cached, ok := youtube.videoDetails[videoId]
+			hit := ok && time.Since(cached.fetchedAt) < youtube.videoDetailsTTL
+			countCacheLookup("videoDetails", hit)
+			if hit {
+				details[videoId] = cached
+			} else {
+				missing = append(missing, videoId)
+			}
+		}
+		youtube.videoDetailsMu.Unlock()
+
+		if len(missing) > 0 {
+			fetched, err := youtube.fetchVideoDetails(missing)
+			if err != nil {
+				return err
+			}
+			for videoId, video := range fetched {
+				details[videoId] = video
+			}
+		}
+
+		for i, videoId := range batch {
+			video, ok := details[videoId]
+			if !ok {
+				continue
+			}
+			err := fn(start+i, video)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (youtube *YouTube) fetchVideoDetails(
+	videoIds []string,
+) (map[string]*VideoDetails, error) {
+	const endpoint = "videos/"
+
+	q := url.Values{}
+	q.Set("part", "snippet,contentDetails,statistics,liveStreamingDetails,recordingDetails")
+	q.Set("id", strings.Join(videoIds, ","))
+	q.Set("maxResults", fmt.Sprint(len(videoIds)))
+
+	body, err := youtube.getJson(endpoint, q)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := body["items"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(
+			"error in YouTube Data API response from %s. Key 'items' not found",
+			endpoint,
+		)
+	}
+
+	details := make(map[string]*VideoDetails, len(items))
+	fetchedAt := time.Now()
+
+	for i := 0; i < len(items); i++ {
+		item, ok := items[i].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Cannot access items[%d]",
+				endpoint,
+				i,
+			)
+		}
+
+		videoId, ok := item["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['id'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		contentDetails, ok := item["contentDetails"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['contentDetails'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		video := &VideoDetails{
+			VideoId:   videoId,
+			fetchedAt: fetchedAt,
+		}
+
+		err := parseContentDetails(contentDetails, video)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s for %s: %w",
+				endpoint,
+				videoId,
+				err,
+			)
+		}
+
+		snippet, ok := item["snippet"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['snippet'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		video.PublishedAt, ok = snippet["publishedAt"].(string)
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['snippet']['publishedAt'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		video.Title, ok = snippet["title"].(string)
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['snippet']['title'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		video.Description, _ = snippet["description"].(string)
+
+		thumbnails, _ := snippet["thumbnails"].(map[string]interface{})
+		for _, size := range []string{"standard", "high", "medium", "default"} {
+			if thumbnail, ok := thumbnails[size].(map[string]interface{}); ok {
+				video.Thumbnail, _ = thumbnail["url"].(string)
+				break
+			}
+		}
+
+		tags, _ := snippet["tags"].([]interface{})
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				video.Tags = append(video.Tags, tag)
+			}
+		}
+
+		if statistics, ok := item["statistics"].(map[string]interface{}); ok {
+			video.ViewCount, _ = statistics["viewCount"].(string)
+			video.LikeCount, _ = statistics["likeCount"].(string)
+			video.CommentCount, _ = statistics["commentCount"].(string)
+		}
+
+		liveStreamingDetails, ok := item["liveStreamingDetails"].(map[string]interface{})
+		if ok {
+			video.Livestream = true
+			video.ActualStartTime, _ = liveStreamingDetails["actualStartTime"].(string)
+		}
+
+		if recordingDetails, ok := item["recordingDetails"].(map[string]interface{}); ok {
+			video.RecordingDate, _ = recordingDetails["recordingDate"].(string)
+		}
+
+		details[videoId] = video
+	}
+
+	return details, nil
+}
+
+// GetVideosMetadata assembles VideoMetadata for uploads of one channel from the
+// cached video details, fetching the channel only once.
+func (youtube *YouTube) GetVideosMetadata(
+	channelId string, videoIds []string,
+) ([]*VideoMetadata, error) {
+	channel, err := youtube.GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := youtube.GetVideoDetails(videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make([]*VideoMetadata, 0, len(videoIds))
+	for _, videoId := range videoIds {
+		video, ok := details[videoId]
+		if !ok {
+			continue
+		}
+
+		metadata = append(metadata, &VideoMetadata{
+			VideoId:          video.VideoId,
+			VideoTitle:       video.Title,
+			VideoThumbnail:   video.Thumbnail,
+			ViewCount:        video.ViewCount,
+			LikeCount:        video.LikeCount,
+			CommentCount:     video.CommentCount,
+			PublishedAt:      video.PublishedAt,
+			Duration:         video.Duration,
+			Definition:       video.Definition,
+			Caption:          video.Caption,
+			LicensedContent:  video.LicensedContent,
+			ChannelId:        channel.ChannelId,
+			ChannelTitle:     channel.ChannelTitle,
+			ChannelThumbnail: channel.ChannelThumbnail,
+			ChannelCustomUrl: channel.ChannelCustomUrl,
+			SubscriberCount:  channel.SubscriberCount,
+			VideoCount:       channel.VideoCount,
+		})
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,80 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors wrap ErrNotFound or ErrInvalidArgument when they are caused by what
+// the caller asked for rather than by a failure, so their messages can be
+// shown to clients.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+type classifiedError struct {
+	class   error
+	message string
+}
+
+func (err *classifiedError) Error() string {
+	return err.message
+}
+
+func (err *classifiedError) Unwrap() error {
+	return err.class
+}
+
+func notFoundf(format string, args ...interface{}) error {
+	return &classifiedError{ErrNotFound, fmt.Sprintf(format, args...)}
+}
+
+func invalidf(format string, args ...interface{}) error {
+	return &classifiedError{ErrInvalidArgument, fmt.Sprintf(format, args...)}
+}
+
+// An UpstreamError is a call to the Data API that failed, either without a
+// response or with an error status.
+type UpstreamError struct {
+	Endpoint string
+	// Status is zero when no response arrived.
+	Status int
+	// Reason is the reason the API gave, such as quotaExceeded.
+	Reason string
+	Err    error
+}
+
+func (err *UpstreamError) Error() string {
+	if err.Status == 0 {
+		return fmt.Sprintf("call to YouTube API endpoint %s failed: %s", err.Endpoint, err.Err)
+	}
+	return fmt.Sprintf(
+		"call to YouTube API endpoint %s failed with status %d (%s)",
+		err.Endpoint, err.Status, err.Reason,
+	)
+}
+
+func (err *UpstreamError) Unwrap() error {
+	return err.Err
+}
+
+// QuotaExceeded reports whether the API refused the call for lack of quota.
+func (err *UpstreamError) QuotaExceeded() bool {
+	switch err.Reason {
+	case reasonQuotaExceeded, "dailyLimitExceeded", "rateLimitExceeded", "userRateLimitExceeded":
+		return true
+	}
+	return false
+}
+
+// newUpstreamError reads the reason from the error response and closes it.
+func newUpstreamError(endpoint string, res *http.Response) error {
+	defer res.Body.Close()
+	return &UpstreamError{
+		Endpoint: endpoint,
+		Status:   res.StatusCode,
+		Reason:   apiErrorReason(res.Status, res.Body),
+	}
+}
@@ -0,0 +1,184 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// KeyProbeTTL is how long a successful key probe is reused. Probes cost quota,
+// so readiness checks must not call the API on every request.
+const KeyProbeTTL = 5 * time.Minute
+
+// KeyProbeFailureTTL is shorter, so a fixed key is noticed soon.
+const KeyProbeFailureTTL = 30 * time.Second
+
+const keyProbeTimeout = 5 * time.Second
+
+const reasonQuotaExceeded = "quotaExceeded"
+
+// KeyStatus describes one API key. Keys are identified by their position in
+// the configuration so the key itself is never exposed.
+type KeyStatus struct {
+	Key           int
+	Ok            bool
+	QuotaExceeded bool
+	Reason        string
+	CheckedAt     time.Time
+}
+
+type keyState struct {
+	status  KeyStatus
+	expires time.Time
+
+	// exhaustedUntil is set when a call reports the daily quota as exceeded.
+	exhaustedUntil time.Time
+}
+
+// quotaReset returns when the daily quota, which resets at midnight Pacific
+// time, is next replenished.
+func quotaReset(now time.Time) time.Time {
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return now.Add(24 * time.Hour)
+	}
+	local := now.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, location)
+}
+
+// apiErrorReason extracts the reason of the first error in a Data API error
+// response, or its HTTP status when the body holds none.
+func apiErrorReason(status string, body io.Reader) string {
+	var response struct {
+		Error struct {
+			Errors []struct {
+				Reason string
+			}
+		}
+	}
+
+	err := json.NewDecoder(body).Decode(&response)
+	if err != nil || len(response.Error.Errors) == 0 || response.Error.Errors[0].Reason == "" {
+		return status
+	}
+	return response.Error.Errors[0].Reason
+}
+
+func (youtube *client) keyStateFor(key string) *keyState {
+	youtube.keyStatesMu.Lock()
+	defer youtube.keyStatesMu.Unlock()
+
+	state, ok := youtube.keyStates[key]
+	if !ok {
+		state = &keyState{}
+		youtube.keyStates[key] = state
+	}
+	return state
+}
+
+func (youtube *client) markQuotaExceeded(key string) {
+	now := time.Now()
+	state := youtube.keyStateFor(key)
+
+	youtube.keyStatesMu.Lock()
+	state.exhaustedUntil = quotaReset(now)
+	youtube.keyStatesMu.Unlock()
+}
+
+// ProbeKeys reports whether each configured key can call the API, probing
+// keys whose last result has expired. Keys known to be out of quota are not
+// probed again until the quota resets.
+func (youtube *YouTube) ProbeKeys() []KeyStatus {
+	statuses := make([]KeyStatus, len(youtube.apiKeys))
+
+	var wg sync.WaitGroup
+	for i, key := range youtube.apiKeys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			statuses[i] = youtube.probeKey(key)
+			statuses[i].Key = i + 1
+		}(i, key)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func (youtube *YouTube) probeKey(key string) KeyStatus {
+	now := time.Now()
+	state := youtube.keyStateFor(key)
+
+	youtube.keyStatesMu.Lock()
+	if now.Before(state.exhaustedUntil) {
+		status := KeyStatus{QuotaExceeded: true, Reason: reasonQuotaExceeded, CheckedAt: now}
+		youtube.keyStatesMu.Unlock()
+		return status
+	}
+	if now.Before(state.expires) {
+		status := state.status
+		youtube.keyStatesMu.Unlock()
+		return status
+	}
+	youtube.keyStatesMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(youtube.ctx, keyProbeTimeout)
+	defer cancel()
+
+	status, err := youtube.WithContext(ctx).callProbe(key)
+	if err != nil {
+		status = KeyStatus{Reason: fmt.Sprintf("unreachable: %s", err)}
+	}
+	status.CheckedAt = now
+
+	// A probe cut short by the caller says nothing about the key.
+	if youtube.ctx.Err() != nil {
+		return status
+	}
+
+	ttl := KeyProbeTTL
+	if !status.Ok {
+		ttl = KeyProbeFailureTTL
+	}
+
+	youtube.keyStatesMu.Lock()
+	state.status = status
+	state.expires = now.Add(ttl)
+	youtube.keyStatesMu.Unlock()
+
+	return status
+}
+
+// callProbe makes the cheapest call available, fetching a single video
+// category, with the given key.
+func (youtube *YouTube) callProbe(key string) (KeyStatus, error) {
+	const endpoint = "videoCategories/"
+
+	requestUrl, err := url.Parse(BaseUrl + endpoint)
+	if err != nil {
+		return KeyStatus{}, err
+	}
+
+	q := requestUrl.Query()
+	q.Set("key", key)
+	q.Set("part", "id")
+	q.Set("id", "1")
+	requestUrl.RawQuery = q.Encode()
+
+	res, err := youtube.get(endpoint, requestUrl)
+	if err != nil {
+		return KeyStatus{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 200 {
+		return KeyStatus{Ok: true}, nil
+	}
+
+	reason := apiErrorReason(res.Status, res.Body)
+	return KeyStatus{QuotaExceeded: reason == reasonQuotaExceeded, Reason: reason}, nil
+}
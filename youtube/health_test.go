@@ -0,0 +1,51 @@
+package youtube
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestApiKeySkipsExhaustedKeys(t *testing.T) {
+	var mu sync.Mutex
+	used := map[string]int{}
+	exhausted := map[string]bool{"spent": true}
+
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		key := req.URL.Query().Get("key")
+		mu.Lock()
+		used[key]++
+		out := exhausted[key]
+		mu.Unlock()
+
+		if out {
+			return jsonResponse(req, http.StatusForbidden,
+				`{"error": {"errors": [{"reason": "quotaExceeded"}]}}`), nil
+		}
+		return jsonResponse(req, http.StatusOK,
+			`{"items": [{"contentDetails": {"relatedPlaylists": {"uploads": "UUchannel"}}}]}`), nil
+	})
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"spent", "fresh"}})
+
+	// The first call with the spent key fails and marks it out of quota.
+	for i := 0; i < 10; i++ {
+		youtube.GetUploadsPlaylist("UCchannel", ContentTypeAll)
+	}
+	if used["spent"] != 1 {
+		t.Errorf("spent key used %d times, want once", used["spent"])
+	}
+	if used["fresh"] != 9 {
+		t.Errorf("fresh key used %d times, want 9", used["fresh"])
+	}
+
+	// With every key out of quota, calls go ahead and report the quota error.
+	exhausted["fresh"] = true
+	youtube.GetUploadsPlaylist("UCchannel", ContentTypeAll)
+	_, err := youtube.GetUploadsPlaylist("UCchannel", ContentTypeAll)
+	var upstream *UpstreamError
+	if !errors.As(err, &upstream) || !upstream.QuotaExceeded() {
+		t.Errorf("error with every key spent = %v, want a quota error", err)
+	}
+}
@@ -1,17 +1,24 @@
 package youtube
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
 	"net/http"
 	"net/url"
-	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"yt_search_server/logging"
 )
 
-func parseVideoId(idOrUrl string) (string, error) {
+var ErrPlaylistNotFound error = &classifiedError{ErrNotFound, "playlist not found"}
+
+func ParseVideoId(idOrUrl string) (string, error) {
 	oIdOrUrl := idOrUrl
 
 	if !strings.HasPrefix(idOrUrl, "http://") &&
@@ -31,7 +38,7 @@ func parseVideoId(idOrUrl string) (string, error) {
 		if len(match) > 1 {
 			return match[1], nil
 		} else {
-			return "", fmt.Errorf("video ID not found in URL: %s", oIdOrUrl)
+			return "", invalidf("video ID not found in URL: %s", oIdOrUrl)
 		}
 	} else if strings.HasPrefix(idOrUrl, "youtu.be") {
 		pattern := `^youtu.be/([^?]*)\??.*$`
@@ -41,14 +48,16 @@ func parseVideoId(idOrUrl string) (string, error) {
 		if len(match) > 1 {
 			return match[1], nil
 		} else {
-			return "", fmt.Errorf("video ID not found in URL: %s", oIdOrUrl)
+			return "", invalidf("video ID not found in URL: %s", oIdOrUrl)
 		}
 	} else {
-		return "", fmt.Errorf("unrecognized youtube URL format: %s", oIdOrUrl)
+		return "", invalidf("unrecognized youtube URL format: %s", oIdOrUrl)
 	}
 }
 
-func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
+func (youtube *YouTube) GetUploadsPlaylist(
+	channelId string, contentType ContentType,
+) (string, error) {
 	const endpoint = "channels/"
 
 	requestUrl, err := url.Parse(BaseUrl + endpoint)
@@ -57,22 +66,18 @@ func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
 	}
 
 	q := requestUrl.Query()
-	q.Set("key", youtube.apiKey)
+	q.Set("key", youtube.apiKey())
 	q.Set("id", channelId)
 	q.Set("part", "contentDetails")
 	requestUrl.RawQuery = q.Encode()
 
-	res, err := http.Get(requestUrl.String())
+	res, err := youtube.get(endpoint, requestUrl)
 	if err != nil {
 		return "", err
 	}
 
 	if res.StatusCode != 200 {
-		return "", fmt.Errorf(
-			"call to YouTube API endpoint %s failed with message %s",
-			endpoint,
-			res.Status,
-		)
+		return "", newUpstreamError(endpoint, res)
 	}
 
 	body := make(map[string]interface{})
@@ -85,13 +90,13 @@ func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'items' not found",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 	if len(items) == 0 {
 		return "", fmt.Errorf(
 			"no items found in YouTube Data API response from %s",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
@@ -99,7 +104,7 @@ func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(
 			"error in YouTube Data API response from %s. Cannot access items[0]['contentDetails']",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
@@ -107,7 +112,7 @@ func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(
 			"error in YouTube Data API response from %s. Cannot access items[0]['contentDetails']",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
@@ -115,7 +120,7 @@ func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(
 			"error in YouTube Data API response from %s. Cannot access items[0]['contentDetails']['relatedPlaylists']",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
@@ -123,11 +128,11 @@ func (youtube *YouTube) GetUploadsPlaylist(channelId string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(
 			"error in YouTube Data API response from %s. Cannot access items[0]['contentDetails']['relatedPlaylists']['uploads']",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
-	return uploadsPlaylist, nil
+	return contentType.playlistId(uploadsPlaylist), nil
 }
 
 func (youtube *YouTube) GetPlaylistVideoCount(playlistId string) (int, error) {
@@ -139,24 +144,24 @@ func (youtube *YouTube) GetPlaylistVideoCount(playlistId string) (int, error) {
 	}
 
 	q := requestUrl.Query()
-	q.Set("key", youtube.apiKey)
+	q.Set("key", youtube.apiKey())
 	q.Set("maxResults", "1")
 	q.Set("part", "contentDetails")
 	q.Set("playlistId", playlistId)
 	requestUrl.RawQuery = q.Encode()
 
-	res, err := http.Get(requestUrl.String())
+	res, err := youtube.get(endpoint, requestUrl)
 	if err != nil {
 		return 0, err
 	}
 
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return 0, fmt.Errorf("%w: %s", ErrPlaylistNotFound, playlistId)
+	}
+
 	if res.StatusCode != 200 {
-		return 0, fmt.Errorf(
-			"call to YouTube API endpoint %s failed with message %s. Request URL: %s",
-			endpoint,
-			res.Status,
-			requestUrl.String(),
-		)
+		return 0, newUpstreamError(endpoint, res)
 	}
 
 	body := make(map[string]interface{})
@@ -169,7 +174,7 @@ func (youtube *YouTube) GetPlaylistVideoCount(playlistId string) (int, error) {
 	if !ok {
 		return 0, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'pageInfo' not found",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
@@ -177,7 +182,7 @@ func (youtube *YouTube) GetPlaylistVideoCount(playlistId string) (int, error) {
 	if !ok {
 		return 0, fmt.Errorf(
 			"error in YouTube Data API response from %s. Cannot access pageInfo['totalResults']",
-			requestUrl.String(),
+			endpoint,
 		)
 	}
 
@@ -194,18 +199,27 @@ func (youtube *YouTube) GetPageVideos(
 ) {
 	const endpoint = "playlistItems/"
 
-	requestUrl, err := url.Parse(BaseUrl + endpoint)
-	if err != nil {
+	if youtube.pages != nil {
+		youtube.pages.Add(1)
+	}
+
+	// Only the first error is reported; the rest are logged.
+	fail := func(err error) {
 		select {
 		case chPageTokensErrors <- err:
 		default:
-			fmt.Fprint(os.Stderr, err.Error())
-			return
+			youtube.logger().Error(err.Error())
 		}
 	}
 
+	requestUrl, err := url.Parse(BaseUrl + endpoint)
+	if err != nil {
+		fail(err)
+		return
+	}
+
 	q := requestUrl.Query()
-	q.Set("key", youtube.apiKey)
+	q.Set("key", youtube.apiKey())
 	q.Set("maxResults", "50")
 	q.Set("part", "contentDetails")
 	q.Set("playlistId", playlistId)
@@ -214,28 +228,13 @@ func (youtube *YouTube) GetPageVideos(
 	}
 	requestUrl.RawQuery = q.Encode()
 
-	res, err := http.Get(requestUrl.String())
-	if err != nil {
-		select {
-		case chPageTokensErrors <- err:
-		default:
-			fmt.Fprint(os.Stderr, err.Error())
-			return
-		}
+	res, err := youtube.get(endpoint, requestUrl)
+	if err == nil && res.StatusCode != 200 {
+		err = newUpstreamError(endpoint, res)
 	}
-
-	if res.StatusCode != 200 {
-		select {
-		case chPageTokensErrors <- err:
-		default:
-			fmt.Fprintf(
-				os.Stderr,
-				"call to YouTube API endpoint %s failed with message %s",
-				endpoint,
-				res.Status,
-			)
-			return
-		}
+	if err != nil {
+		fail(err)
+		return
 	}
 
 	body := make(map[string]interface{})
@@ -249,7 +248,7 @@ func (youtube *YouTube) GetPageVideos(
 		select {
 		case chPageTokensDone <- true:
 		default:
-			fmt.Fprintf(os.Stderr, "Attempting to write to unbuffered channel twice.")
+			youtube.logger().Error("Attempting to write to unbuffered channel twice.")
 		}
 	} else {
 		chPageTokens <- nextPageToken
@@ -257,82 +256,227 @@ func (youtube *YouTube) GetPageVideos(
 
 	items, ok := body["items"].([]interface{})
 	if !ok {
-		select {
-		case chPageTokensErrors <- err:
-		default:
-			fmt.Fprintf(
-				os.Stderr,
-				"error in YouTube Data API response from %s. Key 'items' not found",
-				requestUrl.String(),
-			)
-			return
-		}
+		fail(fmt.Errorf(
+			"error in YouTube Data API response from %s. Key 'items' not found",
+			endpoint,
+		))
+		return
 	}
 
 	for i := 0; i < len(items); i++ {
 		item, ok := items[i].(map[string]interface{})
 		if !ok {
-			select {
-			case chPageTokensErrors <- err:
-			default:
-				fmt.Fprintf(
-					os.Stderr,
-					"error in YouTube Data API response from %s. Key items[%d] not found",
-					requestUrl.String(),
-					i,
-				)
-				return
-			}
+			fail(fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d] not found",
+				endpoint,
+				i,
+			))
+			return
 		}
 
 		contentDetails, ok := item["contentDetails"].(map[string]interface{})
 		if !ok {
-			select {
-			case chPageTokensErrors <- err:
-			default:
-				fmt.Fprintf(
-					os.Stderr,
-					"error in YouTube Data API response from %s. Key items[%d]['contentDetails'] not found",
-					requestUrl.String(),
-					i,
-				)
-				return
-			}
+			fail(fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['contentDetails'] not found",
+				endpoint,
+				i,
+			))
+			return
 		}
 
 		videoId, ok := contentDetails["videoId"].(string)
 		if !ok {
-			select {
-			case chPageTokensErrors <- err:
-			default:
-				fmt.Fprintf(
-					os.Stderr,
-					"error in YouTube Data API response from %s. Key items[%d]['contentDetails']['videoId'] not found",
-					requestUrl.String(),
-					i,
-				)
-				return
-			}
+			fail(fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['contentDetails']['videoId'] not found",
+				endpoint,
+				i,
+			))
+			return
 		}
 
 		publishedAt, ok := contentDetails["videoPublishedAt"].(string)
 		if !ok {
-			select {
-			case chPageTokensErrors <- err:
-			default:
-				fmt.Fprintf(
-					os.Stderr,
-					"error in YouTube Data API response from %s. Key items[%d]['contentDetails']['videoPublishedAt'] not found",
-					requestUrl.String(),
-					i,
-				)
-				return
-			}
+			fail(fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['contentDetails']['videoPublishedAt'] not found",
+				endpoint,
+				i,
+			))
+			return
+		}
+
+		select {
+		case chVideos <- PlaylistVideo{VideoId: videoId, PublishedAt: publishedAt}:
+		case <-youtube.ctx.Done():
+			return
+		}
+	}
+}
+
+func (youtube *YouTube) getJson(
+	endpoint string, q url.Values,
+) (map[string]interface{}, error) {
+	requestUrl, err := url.Parse(BaseUrl + endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Set("key", youtube.apiKey())
+	requestUrl.RawQuery = q.Encode()
+
+	res, err := youtube.get(endpoint, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return nil, newUpstreamError(endpoint, res)
+	}
+
+	body := make(map[string]interface{})
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error decoding YouTube Data API response from %s: %w",
+			endpoint,
+			err,
+		)
+	}
+
+	return body, nil
+}
+
+var isoDurationPattern = regexp.MustCompile(
+	`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`,
+)
+
+func parseIsoDuration(duration string) (int, error) {
+	match := isoDurationPattern.FindStringSubmatch(duration)
+	if match == nil || duration == "P" || strings.HasSuffix(duration, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration: %s", duration)
+	}
+
+	unitSeconds := []int{7 * 24 * 3600, 24 * 3600, 3600, 60, 1}
+
+	seconds := 0
+	for i, unit := range unitSeconds {
+		if match[i+1] == "" {
+			continue
 		}
+		value, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration: %s", duration)
+		}
+		seconds += value * unit
+	}
+
+	return seconds, nil
+}
+
+func parseContentDetails(
+	contentDetails map[string]interface{}, details *VideoDetails,
+) error {
+	duration, ok := contentDetails["duration"].(string)
+	if !ok {
+		return fmt.Errorf("key 'duration' not found in contentDetails")
+	}
+
+	seconds, err := parseIsoDuration(duration)
+	if err != nil {
+		return err
+	}
 
-		chVideos <- PlaylistVideo{
-			VideoId:     videoId,
-			PublishedAt: publishedAt,
+	definition, ok := contentDetails["definition"].(string)
+	if !ok {
+		return fmt.Errorf("key 'definition' not found in contentDetails")
+	}
+
+	caption, ok := contentDetails["caption"].(string)
+	if !ok {
+		return fmt.Errorf("key 'caption' not found in contentDetails")
+	}
+
+	licensedContent, _ := contentDetails["licensedContent"].(bool)
+
+	details.Duration = seconds
+	details.Definition = definition
+	details.Caption = caption == "true"
+	details.LicensedContent = licensedContent
+
+	return nil
+}
+
+func (youtube *YouTube) logger() *slog.Logger {
+	return logging.FromContext(youtube.ctx)
+}
+
+// get performs every call to the Data API, so each is counted against and
+// logged with the request that caused it.
+func (youtube *YouTube) get(endpoint string, requestUrl *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(
+		youtube.ctx, http.MethodGet, requestUrl.String(), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.CountUpstreamCall(youtube.ctx)
+
+	query := requestUrl.Query()
+	query.Del("key")
+	logger := youtube.logger().With(
+		"endpoint", endpoint,
+		"query", query.Encode(),
+	)
+
+	start := time.Now()
+	res, err := http.DefaultClient.Do(req)
+	elapsed := time.Since(start)
+	duration := float64(elapsed.Microseconds()) / 1000
+
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	apiCalls.With(endpoint, callOutcome(status, err)).Inc()
+	apiCallDuration.With(endpoint).Observe(elapsed.Seconds())
+	// A request that reached the API is charged even if it failed.
+	if err == nil {
+		quotaUnits.With(endpoint).Add(quotaCostPerCall)
+	}
+
+	if err != nil {
+		// The URL carries the API key, which must not reach logs or clients.
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = BaseUrl + endpoint
 		}
+		logger.Warn("Upstream call failed", "durationMs", duration, "error", err)
+		return nil, &UpstreamError{Endpoint: endpoint, Err: err}
+	}
+
+	if res.StatusCode == http.StatusForbidden {
+		youtube.checkQuota(requestUrl.Query().Get("key"), res)
+	}
+
+	logger.Info("Upstream call", "status", res.StatusCode, "durationMs", duration)
+
+	return res, nil
+}
+
+// checkQuota notes keys whose daily quota is spent, leaving the response body
+// unread for the caller.
+func (youtube *YouTube) checkQuota(key string, res *http.Response) {
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	if apiErrorReason(res.Status, bytes.NewReader(body)) == reasonQuotaExceeded {
+		youtube.logger().Warn("API key out of quota")
+		youtube.markQuotaExceeded(key)
 	}
 }
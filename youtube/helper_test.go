@@ -0,0 +1,41 @@
+package youtube
+
+import "testing"
+
+func TestParseIsoDuration(t *testing.T) {
+	tests := []struct {
+		duration string
+		want     int
+		wantErr  bool
+	}{
+		{duration: "PT0S", want: 0},
+		{duration: "P0D", want: 0},
+		{duration: "PT45S", want: 45},
+		{duration: "PT4M13S", want: 253},
+		{duration: "PT1H", want: 3600},
+		{duration: "P1DT2H", want: 26 * 3600},
+		{duration: "P1W2DT3H4M5S", want: 9*24*3600 + 3*3600 + 4*60 + 5},
+		// Live streams that have not started report no duration.
+		{duration: "", wantErr: true},
+		{duration: "P", wantErr: true},
+		{duration: "PT", wantErr: true},
+		{duration: "P1DT", wantErr: true},
+		{duration: "PT1.5S", wantErr: true},
+		{duration: "PT-5S", wantErr: true},
+		{duration: "PT5S4M", wantErr: true},
+		{duration: "P1Y", wantErr: true},
+		{duration: "1H", wantErr: true},
+		{duration: "pt5s", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseIsoDuration(test.duration)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseIsoDuration(%q) error = %v, want error %v", test.duration, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseIsoDuration(%q) = %d, want %d", test.duration, got, test.want)
+		}
+	}
+}
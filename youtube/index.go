@@ -0,0 +1,83 @@
+package youtube
+
+import (
+	"sync"
+	"time"
+)
+
+const ChannelIndexTTL = time.Hour
+
+type channelIndex struct {
+	builtAt time.Time
+	videos  []PlaylistVideo
+	details map[string]*VideoDetails
+
+	searchOnce sync.Once
+	search     *searchIndex
+	searchErr  error
+
+	seriesOnce sync.Once
+	series     *seriesIndex
+}
+
+func (youtube *YouTube) getChannelIndex(channelId string) (*channelIndex, error) {
+	youtube.channelIndexesMu.Lock()
+	index, ok := youtube.channelIndexes[channelId]
+	youtube.channelIndexesMu.Unlock()
+
+	hit := ok && time.Since(index.builtAt) < youtube.channelIndexTTL
+	countCacheLookup("channelIndex", hit)
+	if hit {
+		return index, nil
+	}
+
+	videos, err := youtube.GetTimeline(channelId, ChannelVideosOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	videoIds := make([]string, len(videos))
+	for i, video := range videos {
+		videoIds[i] = video.VideoId
+	}
+
+	details, err := youtube.GetVideoDetails(videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	index = &channelIndex{
+		builtAt: time.Now(),
+		videos:  videos,
+		details: details,
+	}
+
+	youtube.channelIndexesMu.Lock()
+	youtube.channelIndexes[channelId] = index
+	youtube.channelIndexesMu.Unlock()
+
+	return index, nil
+}
+
+func (index *channelIndex) searchIndex() (*searchIndex, error) {
+	index.searchOnce.Do(func() {
+		index.search, index.searchErr = buildSearchIndex(index.videos, index.details)
+	})
+	return index.search, index.searchErr
+}
+
+func (index *channelIndex) seriesIndex() *seriesIndex {
+	index.seriesOnce.Do(func() {
+		index.series = buildSeriesIndex(index.videos, index.details)
+	})
+	return index.series
+}
+
+func (index *channelIndex) position(videoId string) int {
+	for i, video := range index.videos {
+		if video.VideoId == videoId {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,85 @@
+package youtube
+
+import (
+	"fmt"
+	"sync"
+)
+
+const MaxMergedChannels = 10
+
+func (youtube *YouTube) GetMergedTimeline(
+	channelIds []string, options ChannelVideosOptions,
+) ([]PlaylistVideo, error) {
+	if len(channelIds) == 0 {
+		return nil, invalidf("no channels to merge")
+	}
+	if len(channelIds) > MaxMergedChannels {
+		return nil, invalidf(
+			"cannot merge more than %d channels", MaxMergedChannels,
+		)
+	}
+
+	timelines := make([][]PlaylistVideo, len(channelIds))
+	errs := make([]error, len(channelIds))
+
+	var wg sync.WaitGroup
+	for i, channelId := range channelIds {
+		wg.Add(1)
+		go func(i int, channelId string) {
+			defer wg.Done()
+			timelines[i], errs[i] = youtube.GetTimeline(channelId, options)
+		}(i, channelId)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error building timeline for channel %s: %w", channelIds[i], err,
+			)
+		}
+	}
+
+	seen := make(map[string]bool)
+	merged := []PlaylistVideo{}
+	for _, timeline := range timelines {
+		for _, video := range timeline {
+			if seen[video.VideoId] {
+				continue
+			}
+			seen[video.VideoId] = true
+			merged = append(merged, video)
+		}
+	}
+
+	err := youtube.sortVideos(merged, options.SortKey, options.Descending)
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+func (youtube *YouTube) GetMergedChannelVideos(
+	channelIds []string, videoId string, options ChannelVideosOptions,
+) (*VideoList, error) {
+	videos, err := youtube.GetMergedTimeline(channelIds, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ind := -1
+
+	for i, vid := range videos {
+		if vid.VideoId == videoId {
+			ind = i
+			break
+		}
+	}
+
+	if ind == -1 {
+		return nil, notFoundf("video not found in the merged timeline matching the filters")
+	}
+
+	return youtube.getWindow(videos, ind), nil
+}
@@ -0,0 +1,29 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetMergedTimelineRejectsChannelCount(t *testing.T) {
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected upstream call to %s", req.URL.Path)
+		return nil, errors.New("unexpected call")
+	})
+
+	tooMany := make([]string, MaxMergedChannels+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("UCchannel%d", i)
+	}
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+	for _, channelIds := range [][]string{nil, tooMany} {
+		_, err := youtube.GetMergedTimeline(channelIds, ChannelVideosOptions{})
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("GetMergedTimeline(%d channels) error = %v, want ErrInvalidArgument",
+				len(channelIds), err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package youtube
+
+import (
+	"yt_search_server/metrics"
+)
+
+// Every call to the list endpoints used here costs one unit of the daily
+// Data API quota.
+const quotaCostPerCall = 1
+
+var (
+	apiCalls = metrics.Default.NewCounterVec(
+		"youtube_api_calls_total",
+		"Calls to the YouTube Data API by endpoint and outcome.",
+		"endpoint", "outcome",
+	)
+	apiCallDuration = metrics.Default.NewHistogramVec(
+		"youtube_api_call_duration_seconds",
+		"Latency of calls to the YouTube Data API.",
+		metrics.DurationBuckets,
+		"endpoint",
+	)
+	quotaUnits = metrics.Default.NewCounterVec(
+		"youtube_api_quota_units_total",
+		"YouTube Data API quota units spent, by endpoint.",
+		"endpoint",
+	)
+	cacheLookups = metrics.Default.NewCounterVec(
+		"youtube_cache_lookups_total",
+		"Cache lookups by cache and result (hit or miss).",
+		"cache", "result",
+	)
+	paginationJobs = metrics.Default.NewGaugeVec(
+		"youtube_pagination_jobs_in_flight",
+		"Playlists currently being paged through.",
+	)
+	channelVideosPages = metrics.Default.NewHistogramVec(
+		"youtube_channel_videos_pages",
+		"Playlist pages fetched to answer one GetChannelVideos call.",
+		[]float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500},
+	)
+)
+
+func callOutcome(status int, err error) string {
+	switch {
+	case err != nil:
+		return "network_error"
+	case status < 300:
+		return "success"
+	case status == 403:
+		// Quota exhaustion and invalid keys both surface as 403.
+		return "forbidden"
+	case status < 500:
+		return "client_error"
+	default:
+		return "server_error"
+	}
+}
+
+func countCacheLookup(cache string, hit bool) {
+	if hit {
+		cacheLookups.With(cache, "hit").Inc()
+	} else {
+		cacheLookups.With(cache, "miss").Inc()
+	}
+}
@@ -0,0 +1,76 @@
+package youtube
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"yt_search_server/metrics"
+)
+
+// scrapeSamples fetches the default registry as /metrics would serve it and
+// returns each sample's value by its name and labels.
+func scrapeSamples(t *testing.T) map[string]float64 {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	metrics.Default.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	samples := make(map[string]float64)
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		space := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[space+1:], 64)
+		if space == -1 || err != nil {
+			t.Fatalf("malformed sample %q", line)
+		}
+		samples[line[:space]] = value
+	}
+	return samples
+}
+
+func TestApiCallMetrics(t *testing.T) {
+	statuses := []int{http.StatusOK, http.StatusForbidden, http.StatusInternalServerError}
+	calls := 0
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		status := statuses[calls]
+		calls++
+		if status != http.StatusOK {
+			return jsonResponse(req, status, `{"error": {"code": 0, "errors": []}}`), nil
+		}
+		return jsonResponse(req, status, channelResponse("")), nil
+	})
+
+	before := scrapeSamples(t)
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+	for range statuses {
+		youtube.GetChannel("UCchannel")
+	}
+
+	after := scrapeSamples(t)
+
+	want := map[string]float64{
+		`youtube_api_calls_total{endpoint="channels/",outcome="success"}`:          1,
+		`youtube_api_calls_total{endpoint="channels/",outcome="forbidden"}`:        1,
+		`youtube_api_calls_total{endpoint="channels/",outcome="server_error"}`:     1,
+		`youtube_api_quota_units_total{endpoint="channels/"}`:                      3,
+		`youtube_api_call_duration_seconds_count{endpoint="channels/"}`:            3,
+		`youtube_api_call_duration_seconds_bucket{endpoint="channels/",le="+Inf"}`: 3,
+	}
+	for sample, delta := range want {
+		if _, ok := after[sample]; !ok {
+			t.Errorf("%s is not exposed", sample)
+			continue
+		}
+		if got := after[sample] - before[sample]; got != delta {
+			t.Errorf("%s rose by %g, want %g", sample, got, delta)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package youtube
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const MaxOnThisDayTolerance = 30
+
+type OnThisDayVideo struct {
+	VideoId     string    `json:"videoId"`
+	Title       string    `json:"title"`
+	Thumbnail   string    `json:"thumbnail"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Position    int       `json:"position"`
+	DayOffset   int       `json:"dayOffset"`
+}
+
+type OnThisDayYear struct {
+	Year     int               `json:"year"`
+	YearsAgo int               `json:"yearsAgo"`
+	Count    int               `json:"count"`
+	Videos   []*OnThisDayVideo `json:"videos"`
+}
+
+type OnThisDay struct {
+	Month     int              `json:"month"`
+	Day       int              `json:"day"`
+	Timezone  string           `json:"timezone"`
+	Tolerance int              `json:"tolerance"`
+	Count     int              `json:"count"`
+	Years     []*OnThisDayYear `json:"years"`
+}
+
+func daysBetween(a time.Time, b time.Time) int {
+	// Compare calendar dates at noon UTC so DST changes cannot skew the count.
+	dateA := time.Date(a.Year(), a.Month(), a.Day(), 12, 0, 0, 0, time.UTC)
+	dateB := time.Date(b.Year(), b.Month(), b.Day(), 12, 0, 0, 0, time.UTC)
+	return int(dateA.Sub(dateB).Hours() / 24)
+}
+
+// ValidateOnThisDay checks the date and tolerance of an on-this-day lookup,
+// so callers can reject them before spending quota on the channel.
+func ValidateOnThisDay(month int, day int, tolerance int) error {
+	if month < 1 || month > 12 {
+		return invalidf("month must be between 1 and 12")
+	}
+	// 2000 is a leap year, so this accepts February 29th.
+	if day < 1 || time.Date(2000, time.Month(month), day, 0, 0, 0, 0, time.UTC).Month() != time.Month(month) {
+		return invalidf("day %d does not exist in month %d", day, month)
+	}
+	if tolerance < 0 || tolerance > MaxOnThisDayTolerance {
+		return invalidf(
+			"tolerance must be between 0 and %d days", MaxOnThisDayTolerance,
+		)
+	}
+	return nil
+}
+
+func (youtube *YouTube) GetOnThisDay(
+	channelId string,
+	month int,
+	day int,
+	tolerance int,
+	location *time.Location,
+) (*OnThisDay, error) {
+	err := ValidateOnThisDay(month, day, tolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := youtube.getChannelIndex(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	currentYear := time.Now().In(location).Year()
+	years := make(map[int]*OnThisDayYear)
+
+	for position, video := range index.videos {
+		publishedAt, err := parseTimestamp(video.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read video %s: %w", video.VideoId, err)
+		}
+		local := publishedAt.In(location)
+
+		// A tolerance window around January 1st reaches into the previous
+		// and next calendar years.
+		for year := local.Year() - 1; year <= local.Year()+1; year++ {
+			if year >= currentYear {
+				continue
+			}
+
+			target := time.Date(year, time.Month(month), day, 0, 0, 0, 0, location)
+			if target.Month() != time.Month(month) && tolerance == 0 {
+				// February 29th only exists in leap years.
+				continue
+			}
+
+			offset := daysBetween(local, target)
+			if offset < -tolerance || offset > tolerance {
+				continue
+			}
+
+			onThisDayVideo := &OnThisDayVideo{
+				VideoId:     video.VideoId,
+				PublishedAt: publishedAt,
+				Position:    position,
+				DayOffset:   offset,
+			}
+			if videoDetails, ok := index.details[video.VideoId]; ok {
+				onThisDayVideo.Title = videoDetails.Title
+				onThisDayVideo.Thumbnail = videoDetails.Thumbnail
+			}
+
+			group, ok := years[year]
+			if !ok {
+				group = &OnThisDayYear{
+					Year:     year,
+					YearsAgo: currentYear - year,
+					Videos:   []*OnThisDayVideo{},
+				}
+				years[year] = group
+			}
+			group.Videos = append(group.Videos, onThisDayVideo)
+			group.Count++
+			break
+		}
+	}
+
+	onThisDay := &OnThisDay{
+		Month:     month,
+		Day:       day,
+		Timezone:  location.String(),
+		Tolerance: tolerance,
+		Years:     []*OnThisDayYear{},
+	}
+
+	for _, group := range years {
+		onThisDay.Years = append(onThisDay.Years, group)
+		onThisDay.Count += group.Count
+	}
+
+	sort.Slice(onThisDay.Years, func(i, j int) bool {
+		return onThisDay.Years[i].Year > onThisDay.Years[j].Year
+	})
+
+	return onThisDay, nil
+}
@@ -0,0 +1,394 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const PlaylistIndexTTL = time.Hour
+
+const playlistIndexWorkers = 5
+
+type PlaylistMembership struct {
+	Playlist *ChannelPlaylist
+	Position int
+}
+
+type VideoPlaylists struct {
+	VideoId   string
+	Count     int
+	Playlists []*PlaylistMembership
+}
+
+type playlistIndex struct {
+	builtAt     time.Time
+	memberships map[string][]*PlaylistMembership
+}
+
+func (youtube *YouTube) GetChannelPlaylists(
+	channelId string, pageToken string,
+) (*ChannelPlaylists, error) {
+	const endpoint = "playlists/"
+
+	q := url.Values{}
+	q.Set("part", "snippet,contentDetails")
+	q.Set("channelId", channelId)
+	q.Set("maxResults", "50")
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+
+	body, err := youtube.getJson(endpoint, q)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := body["items"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf(
+			"error in YouTube Data API response from %s. Key 'items' not found",
+			endpoint,
+		)
+	}
+
+	playlists := make([]*ChannelPlaylist, 0, len(items))
+
+	for i := 0; i < len(items); i++ {
+		item, ok := items[i].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Cannot access items[%d]",
+				endpoint,
+				i,
+			)
+		}
+
+		id, ok := item["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['id'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		snippet, ok := item["snippet"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['snippet'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		title, ok := snippet["title"].(string)
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key items[%d]['snippet']['title'] not found",
+				endpoint,
+				i,
+			)
+		}
+
+		// Empty playlists have no thumbnails, so a missing one is not an error.
+		thumbnailUrl := ""
+		thumbnails, _ := snippet["thumbnails"].(map[string]interface{})
+		if thumbnail, ok := thumbnails["medium"].(map[string]interface{}); ok {
+			thumbnailUrl, _ = thumbnail["url"].(string)
+		}
+
+		videoCount := 0
+		contentDetails, ok := item["contentDetails"].(map[string]interface{})
+		if ok {
+			itemCount, _ := contentDetails["itemCount"].(float64)
+			videoCount = int(math.Round(itemCount))
+		}
+
+		playlists = append(playlists, &ChannelPlaylist{
+			Id:         id,
+			Title:      title,
+			Thumbnail:  thumbnailUrl,
+			VideoCount: videoCount,
+		})
+	}
+
+	totalResults := len(playlists)
+	if pageInfo, ok := body["pageInfo"].(map[string]interface{}); ok {
+		if total, ok := pageInfo["totalResults"].(float64); ok {
+			totalResults = int(math.Round(total))
+		}
+	}
+
+	nextPageToken, _ := body["nextPageToken"].(string)
+	prevPageToken, _ := body["prevPageToken"].(string)
+
+	return &ChannelPlaylists{
+		Count:         len(playlists),
+		TotalResults:  totalResults,
+		NextPageToken: nextPageToken,
+		PrevPageToken: prevPageToken,
+		Playlists:     playlists,
+	}, nil
+}
+
+func (youtube *YouTube) GetPlaylistPositions(
+	playlistId string,
+) (map[string][]int, error) {
+	const endpoint = "playlistItems/"
+
+	positions := make(map[string][]int)
+	pageToken := ""
+
+	for {
+		q := url.Values{}
+		q.Set("part", "snippet")
+		q.Set("playlistId", playlistId)
+		q.Set("maxResults", "50")
+		if pageToken != "" {
+			q.Set("pageToken", pageToken)
+		}
+
+		body, err := youtube.getJson(endpoint, q)
+		if err != nil {
+			return nil, err
+		}
+
+		items, ok := body["items"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key 'items' not found",
+				endpoint,
+			)
+		}
+
+		for i := 0; i < len(items); i++ {
+			item, ok := items[i].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Cannot access items[%d]",
+					endpoint,
+					i,
+				)
+			}
+
+			snippet, ok := item["snippet"].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Key items[%d]['snippet'] not found",
+					endpoint,
+					i,
+				)
+			}
+
+			position, ok := snippet["position"].(float64)
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Key items[%d]['snippet']['position'] not found",
+					endpoint,
+					i,
+				)
+			}
+
+			resourceId, ok := snippet["resourceId"].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Key items[%d]['snippet']['resourceId'] not found",
+					endpoint,
+					i,
+				)
+			}
+
+			videoId, ok := resourceId["videoId"].(string)
+			if !ok {
+				// Playlist entries can point at channels or other playlists.
+				continue
+			}
+
+			positions[videoId] = append(
+				positions[videoId], int(math.Round(position)),
+			)
+		}
+
+		nextPageToken, ok := body["nextPageToken"].(string)
+		if !ok || nextPageToken == "" {
+			return positions, nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+func (youtube *YouTube) GetVideoPlaylists(
+	channelId string, videoId string,
+) (*VideoPlaylists, error) {
+	index, err := youtube.getPlaylistIndex(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships := index.memberships[videoId]
+	if memberships == nil {
+		memberships = []*PlaylistMembership{}
+	}
+
+	return &VideoPlaylists{
+		VideoId:   videoId,
+		Count:     len(memberships),
+		Playlists: memberships,
+	}, nil
+}
+
+// A playlistIndexBuild is a build of a channel's playlist index in progress.
+// Concurrent lookups of the channel wait for it rather than starting their
+// own, as each build reads every playlist of the channel.
+type playlistIndexBuild struct {
+	done  chan struct{}
+	index *playlistIndex
+	err   error
+}
+
+func (youtube *YouTube) getPlaylistIndex(
+	channelId string,
+) (*playlistIndex, error) {
+	for {
+		youtube.playlistIndexesMu.Lock()
+		index, ok := youtube.playlistIndexes[channelId]
+		hit := ok && time.Since(index.builtAt) < youtube.playlistIndexTTL
+		build, building := youtube.playlistIndexBuilds[channelId]
+		if !hit && !building {
+			build = &playlistIndexBuild{done: make(chan struct{})}
+			youtube.playlistIndexBuilds[channelId] = build
+		}
+		youtube.playlistIndexesMu.Unlock()
+
+		countCacheLookup("playlistIndex", hit)
+		if hit {
+			return index, nil
+		}
+
+		if !building {
+			youtube.runPlaylistIndexBuild(channelId, build)
+			return build.index, build.err
+		}
+
+		select {
+		case <-build.done:
+		case <-youtube.ctx.Done():
+			return nil, youtube.ctx.Err()
+		}
+
+		// The build was abandoned by the request that started it, which need
+		// not stop this one.
+		canceled := errors.Is(build.err, context.Canceled) ||
+			errors.Is(build.err, context.DeadlineExceeded)
+		if canceled && youtube.ctx.Err() == nil {
+			continue
+		}
+		return build.index, build.err
+	}
+}
+
+func (youtube *YouTube) runPlaylistIndexBuild(
+	channelId string, build *playlistIndexBuild,
+) {
+	defer close(build.done)
+
+	build.index, build.err = youtube.buildPlaylistIndex(channelId)
+
+	youtube.playlistIndexesMu.Lock()
+	defer youtube.playlistIndexesMu.Unlock()
+
+	if build.err == nil {
+		youtube.playlistIndexes[channelId] = build.index
+	}
+	delete(youtube.playlistIndexBuilds, channelId)
+}
+
+func (youtube *YouTube) buildPlaylistIndex(
+	channelId string,
+) (*playlistIndex, error) {
+	playlists := []*ChannelPlaylist{}
+	pageToken := ""
+
+	for {
+		page, err := youtube.GetChannelPlaylists(channelId, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		playlists = append(playlists, page.Playlists...)
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	type playlistResult struct {
+		index     int
+		positions map[string][]int
+		err       error
+	}
+
+	chPlaylists := make(chan int)
+	chResults := make(chan playlistResult, len(playlists))
+
+	var wg sync.WaitGroup
+	for w := 0; w < playlistIndexWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range chPlaylists {
+				positions, err := youtube.GetPlaylistPositions(playlists[i].Id)
+				chResults <- playlistResult{
+					index:     i,
+					positions: positions,
+					err:       err,
+				}
+			}
+		}()
+	}
+
+	for i := range playlists {
+		chPlaylists <- i
+	}
+	close(chPlaylists)
+	wg.Wait()
+	close(chResults)
+
+	results := make([]map[string][]int, len(playlists))
+	for result := range chResults {
+		if result.err != nil {
+			return nil, fmt.Errorf(
+				"error indexing playlist %s: %w",
+				playlists[result.index].Id,
+				result.err,
+			)
+		}
+		results[result.index] = result.positions
+	}
+
+	// Walk the playlists in listing order so memberships come out stable.
+	memberships := make(map[string][]*PlaylistMembership)
+	for i, positions := range results {
+		for videoId, videoPositions := range positions {
+			for _, position := range videoPositions {
+				memberships[videoId] = append(
+					memberships[videoId],
+					&PlaylistMembership{
+						Playlist: playlists[i],
+						Position: position,
+					},
+				)
+			}
+		}
+	}
+
+	return &playlistIndex{
+		builtAt:     time.Now(),
+		memberships: memberships,
+	}, nil
+}
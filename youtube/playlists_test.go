@@ -0,0 +1,265 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakePlaylist struct {
+	id    string
+	title string
+	// entries are the video IDs in playlist order; an empty one is an entry
+	// that is not a video.
+	entries []string
+}
+
+// usePlaylists answers the Data API calls for a channel's playlists, listing
+// one playlist per page and two entries per page of a playlist. block, when
+// set, answers the listing request starting the nth build. It returns the
+// number of builds started.
+func usePlaylists(
+	t *testing.T,
+	playlists func() []fakePlaylist,
+	block func(build int32, req *http.Request) (*http.Response, error),
+) *atomic.Int32 {
+	builds := &atomic.Int32{}
+
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		page := 0
+		fmt.Sscanf(query.Get("pageToken"), "page%d", &page)
+
+		switch req.URL.Path {
+		case "/youtube/v3/playlists/":
+			if page == 0 {
+				build := builds.Add(1)
+				if block != nil {
+					if res, err := block(build, req); res != nil || err != nil {
+						return res, err
+					}
+				}
+			}
+			listed := playlists()
+			next := ""
+			if page+1 < len(listed) {
+				next = fmt.Sprintf(`"nextPageToken": "page%d",`, page+1)
+			}
+			items := ""
+			if page < len(listed) {
+				items = fmt.Sprintf(`{"id": %q, "snippet": {"title": %q}, "contentDetails": {"itemCount": %d}}`,
+					listed[page].id, listed[page].title, len(listed[page].entries))
+			}
+			return jsonResponse(req, http.StatusOK, fmt.Sprintf(
+				`{%s "pageInfo": {"totalResults": %d}, "items": [%s]}`, next, len(listed), items,
+			)), nil
+
+		case "/youtube/v3/playlistItems/":
+			var entries []string
+			for _, playlist := range playlists() {
+				if playlist.id == query.Get("playlistId") {
+					entries = playlist.entries
+				}
+			}
+			items := []string{}
+			for position := page; position < page+2 && position < len(entries); position++ {
+				resource := `{"kind": "youtube#channel", "channelId": "UCother"}`
+				if entries[position] != "" {
+					resource = fmt.Sprintf(`{"kind": "youtube#video", "videoId": %q}`, entries[position])
+				}
+				items = append(items, fmt.Sprintf(
+					`{"snippet": {"position": %d, "resourceId": %s}}`, position, resource,
+				))
+			}
+			next := ""
+			if page+2 < len(entries) {
+				next = fmt.Sprintf(`"nextPageToken": "page%d",`, page+2)
+			}
+			return jsonResponse(req, http.StatusOK, fmt.Sprintf(
+				`{%s "items": [%s]}`, next, strings.Join(items, ","),
+			)), nil
+		}
+
+		t.Errorf("unexpected upstream call to %s", req.URL.Path)
+		return jsonResponse(req, http.StatusNotFound, `{}`), nil
+	})
+
+	return builds
+}
+
+func staticPlaylists(playlists ...fakePlaylist) func() []fakePlaylist {
+	return func() []fakePlaylist { return playlists }
+}
+
+// memberships lists where a video appears as "playlist@position".
+func memberships(t *testing.T, youtube *YouTube, videoId string) []string {
+	t.Helper()
+
+	playlists, err := youtube.GetVideoPlaylists("UCchannel", videoId)
+	if err != nil {
+		t.Fatalf("GetVideoPlaylists(%s) error = %s", videoId, err)
+	}
+	if playlists.Count != len(playlists.Playlists) {
+		t.Errorf("%s: Count = %d for %d playlists", videoId, playlists.Count, len(playlists.Playlists))
+	}
+
+	found := []string{}
+	for _, membership := range playlists.Playlists {
+		found = append(found, fmt.Sprintf("%s@%d", membership.Playlist.Id, membership.Position))
+	}
+	return found
+}
+
+func TestGetVideoPlaylists(t *testing.T) {
+	usePlaylists(t, staticPlaylists(
+		fakePlaylist{id: "PLfirst", title: "First", entries: []string{"videoA", "videoB", "", "videoA"}},
+		fakePlaylist{id: "PLempty", title: "Empty"},
+		fakePlaylist{id: "PLsecond", title: "Second", entries: []string{"videoB", "videoC", "videoD"}},
+	), nil)
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+
+	tests := []struct {
+		videoId string
+		want    []string
+	}{
+		{videoId: "videoA", want: []string{"PLfirst@0", "PLfirst@3"}},
+		{videoId: "videoB", want: []string{"PLfirst@1", "PLsecond@0"}},
+		{videoId: "videoD", want: []string{"PLsecond@2"}},
+		{videoId: "videoZ", want: []string{}},
+	}
+
+	for _, test := range tests {
+		got := memberships(t, youtube, test.videoId)
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s is in %v, want %v", test.videoId, got, test.want)
+		}
+	}
+
+	playlists, _ := youtube.GetVideoPlaylists("UCchannel", "videoC")
+	if playlist := playlists.Playlists[0].Playlist; playlist.Title != "Second" || playlist.VideoCount != 3 {
+		t.Errorf("videoC is in %+v", playlist)
+	}
+}
+
+func TestPlaylistIndexExpires(t *testing.T) {
+	var mu sync.Mutex
+	entries := []string{"videoA"}
+	builds := usePlaylists(t, func() []fakePlaylist {
+		mu.Lock()
+		defer mu.Unlock()
+		return []fakePlaylist{{id: "PLfirst", title: "First", entries: entries}}
+	}, nil)
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}, PlaylistIndexTTL: time.Hour})
+
+	memberships(t, youtube, "videoA")
+
+	mu.Lock()
+	entries = []string{"videoB", "videoA"}
+	mu.Unlock()
+
+	// Within the TTL the index is served as built.
+	if got := memberships(t, youtube, "videoA"); strings.Join(got, " ") != "PLfirst@0" {
+		t.Errorf("videoA is in %v before the index expired", got)
+	}
+	if builds.Load() != 1 {
+		t.Errorf("index built %d times within its TTL", builds.Load())
+	}
+
+	youtube.playlistIndexesMu.Lock()
+	youtube.playlistIndexes["UCchannel"].builtAt = time.Now().Add(-time.Hour - time.Minute)
+	youtube.playlistIndexesMu.Unlock()
+
+	if got := memberships(t, youtube, "videoA"); strings.Join(got, " ") != "PLfirst@1" {
+		t.Errorf("videoA is in %v after the index expired", got)
+	}
+	if builds.Load() != 2 {
+		t.Errorf("index built %d times, want a rebuild once expired", builds.Load())
+	}
+}
+
+func TestPlaylistIndexBuildsOnce(t *testing.T) {
+	release := make(chan struct{})
+	builds := usePlaylists(t, staticPlaylists(
+		fakePlaylist{id: "PLfirst", title: "First", entries: []string{"videoA"}},
+	), func(build int32, req *http.Request) (*http.Response, error) {
+		<-release
+		return nil, nil
+	})
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+
+	const lookups = 10
+	var wg sync.WaitGroup
+	for i := 0; i < lookups; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := memberships(t, youtube, "videoA"); len(got) != 1 {
+				t.Errorf("videoA is in %v", got)
+			}
+		}()
+	}
+
+	// Let the lookups pile up behind the first build.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if builds.Load() != 1 {
+		t.Errorf("%d concurrent lookups built the index %d times", lookups, builds.Load())
+	}
+}
+
+func TestPlaylistIndexSurvivesAbandonedBuild(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	usePlaylists(t, staticPlaylists(
+		fakePlaylist{id: "PLfirst", title: "First", entries: []string{"videoA"}},
+	), func(build int32, req *http.Request) (*http.Response, error) {
+		if build > 1 {
+			return nil, nil
+		}
+		close(started)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+
+	abandoned := make(chan error, 1)
+	go func() {
+		_, err := youtube.WithContext(ctx).GetVideoPlaylists("UCchannel", "videoA")
+		abandoned <- err
+	}()
+
+	<-started
+	waiting := make(chan []string, 1)
+	go func() {
+		waiting <- memberships(t, youtube, "videoA")
+	}()
+
+	// The first request goes away while the second waits on its build.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-abandoned; err == nil {
+		t.Errorf("abandoned lookup succeeded")
+	}
+	select {
+	case got := <-waiting:
+		if strings.Join(got, " ") != "PLfirst@0" {
+			t.Errorf("videoA is in %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting lookup did not return")
+	}
+}
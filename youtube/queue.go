@@ -0,0 +1,116 @@
+package youtube
+
+const MaxQueueLength = 1000
+
+type QueueItem struct {
+	VideoId  string
+	Title    string
+	Duration int
+	Position int
+}
+
+func timelinePosition(videos []PlaylistVideo, videoId string) int {
+	for i, video := range videos {
+		if video.VideoId == videoId {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetQueue returns a range of the timeline in order. With videoId set the range
+// is the window around that video; otherwise it runs from fromVideoId to
+// toVideoId inclusive. An end left out is MaxQueueLength videos from the
+// other, and with neither the range is the start of the timeline, so that
+// long timelines need no range to be queued.
+func (youtube *YouTube) GetQueue(
+	channelId string,
+	options ChannelVideosOptions,
+	videoId string,
+	fromVideoId string,
+	toVideoId string,
+) ([]*QueueItem, error) {
+	videos, err := youtube.GetTimeline(channelId, options)
+	if err != nil {
+		return nil, err
+	}
+
+	first := 0
+	last := len(videos) - 1
+
+	if videoId != "" {
+		ind := timelinePosition(videos, videoId)
+		if ind == -1 {
+			return nil, notFoundf("video not found in uploads playlist matching the filters")
+		}
+		first = ind - youtube.windowRadius
+		if first < 0 {
+			first = 0
+		}
+		last = ind + youtube.windowRadius
+		if last > len(videos)-1 {
+			last = len(videos) - 1
+		}
+	} else {
+		if fromVideoId != "" {
+			first = timelinePosition(videos, fromVideoId)
+			if first == -1 {
+				return nil, notFoundf("video %s not found in uploads playlist matching the filters", fromVideoId)
+			}
+		}
+		if toVideoId != "" {
+			last = timelinePosition(videos, toVideoId)
+			if last == -1 {
+				return nil, notFoundf("video %s not found in uploads playlist matching the filters", toVideoId)
+			}
+		}
+
+		switch {
+		case toVideoId == "" && last-first+1 > MaxQueueLength:
+			last = first + MaxQueueLength - 1
+		case fromVideoId == "" && last-first+1 > MaxQueueLength:
+			first = last - MaxQueueLength + 1
+		}
+
+		if first > last {
+			return nil, invalidf("range starts after it ends in the timeline")
+		}
+	}
+
+	if last-first+1 > MaxQueueLength {
+		return nil, invalidf(
+			"range holds %d videos, more than the limit of %d",
+			last-first+1,
+			MaxQueueLength,
+		)
+	}
+
+	queue := []*QueueItem{}
+	if last < first {
+		return queue, nil
+	}
+
+	videoIds := make([]string, 0, last-first+1)
+	for _, video := range videos[first : last+1] {
+		videoIds = append(videoIds, video.VideoId)
+	}
+
+	details, err := youtube.GetVideoDetails(videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, video := range videos[first : last+1] {
+		item := &QueueItem{
+			VideoId:  video.VideoId,
+			Position: first + i,
+		}
+		if videoDetails, ok := details[video.VideoId]; ok {
+			item.Title = videoDetails.Title
+			item.Duration = videoDetails.Duration
+		}
+		queue = append(queue, item)
+	}
+
+	return queue, nil
+}
@@ -0,0 +1,98 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// useChannel answers the Data API calls for a channel whose uploads playlist
+// holds total videos, none of which has details.
+func useChannel(t *testing.T, total int) {
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch {
+		case req.URL.Path == "/youtube/v3/channels/":
+			return jsonResponse(req, http.StatusOK,
+				`{"items": [{"contentDetails": {"relatedPlaylists": {"uploads": "UUchannel"}}}]}`), nil
+		case req.URL.Path == "/youtube/v3/playlistItems/":
+			if query.Get("maxResults") == "1" {
+				return jsonResponse(req, http.StatusOK, playlistPage(0, 1, total)), nil
+			}
+			first := 0
+			fmt.Sscanf(query.Get("pageToken"), "page%d", &first)
+			return jsonResponse(req, http.StatusOK, playlistPage(first, 50, total)), nil
+		case req.URL.Path == "/youtube/v3/videos/":
+			return jsonResponse(req, http.StatusOK, `{"items": []}`), nil
+		default:
+			t.Errorf("unexpected upstream call to %s", req.URL.Path)
+			return nil, errors.New("unexpected call")
+		}
+	})
+}
+
+func TestGetQueueRejectsRanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		from  string
+		to    string
+	}{
+		{name: "starts after it ends", total: 10, from: "video0005", to: "video0002"},
+		{name: "too long", total: MaxQueueLength + 1, from: "video0000", to: fmt.Sprintf("video%04d", MaxQueueLength)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useChannel(t, test.total)
+			youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+
+			_, err := youtube.GetQueue("UCchannel", ChannelVideosOptions{}, "", test.from, test.to)
+			if !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("GetQueue error = %v, want ErrInvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestGetQueueDefaultRange(t *testing.T) {
+	const total = MaxQueueLength + 200
+
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		first int
+		last  int
+	}{
+		{name: "no range", first: 0, last: MaxQueueLength - 1},
+		{name: "from", from: "video0100", first: 100, last: MaxQueueLength + 99},
+		{name: "from near the end", from: "video1100", first: 1100, last: total - 1},
+		{name: "to", to: "video1100", first: 1100 - MaxQueueLength + 1, last: 1100},
+		{name: "to near the start", to: "video0005", first: 0, last: 5},
+		{name: "both", from: "video0010", to: "video0020", first: 10, last: 20},
+	}
+
+	useChannel(t, total)
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+
+	for _, test := range tests {
+		queue, err := youtube.GetQueue("UCchannel", ChannelVideosOptions{}, "", test.from, test.to)
+		if err != nil {
+			t.Errorf("%s: GetQueue error = %s", test.name, err)
+			continue
+		}
+
+		first, last := queue[0], queue[len(queue)-1]
+		if first.Position != test.first || last.Position != test.last || len(queue) != test.last-test.first+1 {
+			t.Errorf(
+				"%s: queue holds %d videos from %d to %d, want %d to %d",
+				test.name, len(queue), first.Position, last.Position, test.first, test.last,
+			)
+		}
+		if first.VideoId != fmt.Sprintf("video%04d", test.first) {
+			t.Errorf("%s: queue starts with %s", test.name, first.VideoId)
+		}
+	}
+}
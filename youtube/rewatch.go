@@ -0,0 +1,84 @@
+package youtube
+
+import (
+	"time"
+)
+
+type RewatchItem struct {
+	Metadata   *VideoMetadata
+	Episode    int
+	ReleasedAt time.Time
+}
+
+// GetRewatchSchedule releases a channel's uploads oldest-first, one every
+// interval from start, beginning with fromVideoId (or the first upload). It
+// returns up to limit of the released uploads, most recently released first.
+func (youtube *YouTube) GetRewatchSchedule(
+	channelId string,
+	fromVideoId string,
+	start time.Time,
+	interval time.Duration,
+	limit int,
+	now time.Time,
+) ([]*RewatchItem, error) {
+	if interval <= 0 {
+		return nil, invalidf("release interval must be positive")
+	}
+
+	index, err := youtube.getChannelIndex(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	first := 0
+	if fromVideoId != "" {
+		first = index.position(fromVideoId)
+		if first == -1 {
+			return nil, notFoundf("video not found in uploads playlist")
+		}
+	}
+
+	if now.Before(start) {
+		return []*RewatchItem{}, nil
+	}
+
+	released := int(now.Sub(start)/interval) + 1
+	if released > len(index.videos)-first {
+		released = len(index.videos) - first
+	}
+
+	oldest := released - limit
+	if oldest < 0 {
+		oldest = 0
+	}
+
+	videoIds := []string{}
+	for episode := released - 1; episode >= oldest; episode-- {
+		videoIds = append(videoIds, index.videos[first+episode].VideoId)
+	}
+
+	metadata, err := youtube.GetVideosMetadata(channelId, videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataById := make(map[string]*VideoMetadata, len(metadata))
+	for _, video := range metadata {
+		metadataById[video.VideoId] = video
+	}
+
+	items := []*RewatchItem{}
+	for episode := released - 1; episode >= oldest; episode-- {
+		video, ok := metadataById[index.videos[first+episode].VideoId]
+		if !ok {
+			continue
+		}
+		items = append(items, &RewatchItem{
+			Metadata:   video,
+			Episode:    episode + 1,
+			ReleasedAt: start.Add(time.Duration(episode) * interval),
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,302 @@
+package youtube
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	searchFieldTitle = iota
+	searchFieldTags
+	searchFieldDescription
+)
+
+var searchFieldWeights = []float64{3, 2, 1}
+
+// Letters that do not decompose into a base letter and a combining mark, and
+// apostrophes, so that "let's" and "lets" match.
+var foldReplacer = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th",
+	"'", "", "’", "",
+)
+
+type SearchResult struct {
+	VideoId     string    `json:"videoId"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Position    int       `json:"position"`
+	Score       float64   `json:"score"`
+}
+
+type SearchResults struct {
+	Query        string          `json:"query"`
+	TotalResults int             `json:"totalResults"`
+	Count        int             `json:"count"`
+	Results      []*SearchResult `json:"results"`
+}
+
+type searchPosting struct {
+	doc      int
+	field    int
+	position int
+}
+
+type searchIndex struct {
+	videos      []PlaylistVideo
+	titles      []string
+	publishedAt []time.Time
+	postings    map[string][]searchPosting
+	terms       []string
+}
+
+type searchClause struct {
+	words  []string
+	prefix bool
+}
+
+func foldText(text string) string {
+	folder := transform.Chain(
+		norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC,
+	)
+	folded, _, err := transform.String(folder, text)
+	if err != nil {
+		folded = text
+	}
+	return foldReplacer.Replace(strings.ToLower(folded))
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(foldText(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func parseSearchQuery(query string) []searchClause {
+	clauses := []searchClause{}
+
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		// Odd parts sit between a pair of quotes.
+		if i%2 == 1 {
+			words := tokenize(part)
+			if len(words) > 0 {
+				clauses = append(clauses, searchClause{words: words})
+			}
+			continue
+		}
+
+		for _, field := range strings.Fields(part) {
+			prefix := strings.HasSuffix(field, "*")
+			words := tokenize(strings.TrimSuffix(field, "*"))
+			for j, word := range words {
+				clauses = append(clauses, searchClause{
+					words:  []string{word},
+					prefix: prefix && j == len(words)-1,
+				})
+			}
+		}
+	}
+
+	return clauses
+}
+
+func (youtube *YouTube) SearchChannel(
+	channelId string, query string, limit int,
+) (*SearchResults, error) {
+	clauses := parseSearchQuery(query)
+	if len(clauses) == 0 {
+		return nil, invalidf("search query has no searchable terms")
+	}
+
+	channelIndex, err := youtube.getChannelIndex(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := channelIndex.searchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	scores := index.match(clauses)
+
+	results := make([]*SearchResult, 0, len(scores))
+	for doc, score := range scores {
+		results = append(results, &SearchResult{
+			VideoId:     index.videos[doc].VideoId,
+			Title:       index.titles[doc],
+			PublishedAt: index.publishedAt[doc],
+			Position:    doc,
+			Score:       math.Round(score*1000) / 1000,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Position < results[j].Position
+	})
+
+	totalResults := len(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return &SearchResults{
+		Query:        query,
+		TotalResults: totalResults,
+		Count:        len(results),
+		Results:      results,
+	}, nil
+}
+
+func buildSearchIndex(
+	videos []PlaylistVideo, details map[string]*VideoDetails,
+) (*searchIndex, error) {
+	index := &searchIndex{
+		videos:      videos,
+		titles:      make([]string, len(videos)),
+		publishedAt: make([]time.Time, len(videos)),
+		postings:    make(map[string][]searchPosting),
+	}
+
+	for doc, video := range videos {
+		publishedAt, err := parseTimestamp(video.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("cannot index video %s: %w", video.VideoId, err)
+		}
+		index.publishedAt[doc] = publishedAt
+
+		videoDetails, ok := details[video.VideoId]
+		if !ok {
+			continue
+		}
+		index.titles[doc] = videoDetails.Title
+
+		index.add(doc, searchFieldTitle, tokenize(videoDetails.Title))
+		index.add(doc, searchFieldDescription, tokenize(videoDetails.Description))
+
+		// Leave a gap between tags so phrases cannot span two of them.
+		position := 0
+		for _, tag := range videoDetails.Tags {
+			words := tokenize(tag)
+			for _, word := range words {
+				index.postings[word] = append(
+					index.postings[word],
+					searchPosting{doc, searchFieldTags, position},
+				)
+				position++
+			}
+			position++
+		}
+	}
+
+	index.terms = make([]string, 0, len(index.postings))
+	for term := range index.postings {
+		index.terms = append(index.terms, term)
+	}
+	sort.Strings(index.terms)
+
+	return index, nil
+}
+
+func (index *searchIndex) add(doc int, field int, words []string) {
+	for position, word := range words {
+		index.postings[word] = append(
+			index.postings[word],
+			searchPosting{doc, field, position},
+		)
+	}
+}
+
+func (index *searchIndex) lookup(word string, prefix bool) []searchPosting {
+	if !prefix {
+		return index.postings[word]
+	}
+
+	postings := []searchPosting{}
+	start := sort.SearchStrings(index.terms, word)
+	for i := start; i < len(index.terms) && strings.HasPrefix(index.terms[i], word); i++ {
+		postings = append(postings, index.postings[index.terms[i]]...)
+	}
+	return postings
+}
+
+func (index *searchIndex) clauseHits(clause searchClause) []searchPosting {
+	first := index.lookup(clause.words[0], clause.prefix && len(clause.words) == 1)
+	if len(clause.words) == 1 {
+		return first
+	}
+
+	following := make([]map[searchPosting]bool, len(clause.words)-1)
+	for i, word := range clause.words[1:] {
+		following[i] = make(map[searchPosting]bool)
+		for _, posting := range index.postings[word] {
+			following[i][posting] = true
+		}
+	}
+
+	hits := []searchPosting{}
+	for _, posting := range first {
+		matched := true
+		for i := range following {
+			next := searchPosting{posting.doc, posting.field, posting.position + i + 1}
+			if !following[i][next] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			hits = append(hits, posting)
+		}
+	}
+	return hits
+}
+
+// Every clause has to match. Each one contributes its field-weighted,
+// saturated hit count scaled by how rare the clause is across the channel.
+func (index *searchIndex) match(clauses []searchClause) map[int]float64 {
+	var scores map[int]float64
+
+	for _, clause := range clauses {
+		fieldHits := make(map[int][]float64)
+		for _, posting := range index.clauseHits(clause) {
+			if fieldHits[posting.doc] == nil {
+				fieldHits[posting.doc] = make([]float64, len(searchFieldWeights))
+			}
+			fieldHits[posting.doc][posting.field]++
+		}
+
+		idf := math.Log(1 + float64(len(index.videos))/float64(len(fieldHits)+1))
+
+		clauseScores := make(map[int]float64, len(fieldHits))
+		for doc, hits := range fieldHits {
+			if scores != nil {
+				if _, ok := scores[doc]; !ok {
+					continue
+				}
+			}
+			score := 0.0
+			for field, count := range hits {
+				score += searchFieldWeights[field] * count / (count + 1)
+			}
+			clauseScores[doc] = scores[doc] + idf*score
+		}
+
+		scores = clauseScores
+		if len(scores) == 0 {
+			break
+		}
+	}
+
+	return scores
+}
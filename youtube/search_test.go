@@ -0,0 +1,166 @@
+package youtube
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useChannelIndex caches an index of videos for channelId, so lookups through
+// it need no API calls. The videos are published a day apart, oldest first.
+func useChannelIndex(youtube *YouTube, channelId string, videos ...*VideoDetails) {
+	index := &channelIndex{
+		builtAt: time.Now(),
+		videos:  make([]PlaylistVideo, len(videos)),
+		details: make(map[string]*VideoDetails, len(videos)),
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, video := range videos {
+		if video.VideoId == "" {
+			video.VideoId = fmt.Sprintf("video%d", i)
+		}
+		index.videos[i] = PlaylistVideo{
+			VideoId:     video.VideoId,
+			ChannelId:   channelId,
+			PublishedAt: start.AddDate(0, 0, i).Format(time.RFC3339),
+		}
+		index.details[video.VideoId] = video
+	}
+
+	youtube.channelIndexesMu.Lock()
+	youtube.channelIndexes[channelId] = index
+	youtube.channelIndexesMu.Unlock()
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "Café Crème", want: []string{"cafe", "creme"}},
+		// The same words, written with combining accents.
+		{text: "Cafe\u0301 Cre\u0300me", want: []string{"cafe", "creme"}},
+		{text: "ÉLAN Niño", want: []string{"elan", "nino"}},
+		{text: "Straße Ærø Łódź", want: []string{"strasse", "aero", "lodz"}},
+		{text: "Let's Play! Let’s go", want: []string{"lets", "play", "lets", "go"}},
+		{text: "Part 2: hard-core", want: []string{"part", "2", "hard", "core"}},
+		{text: "東京 tour", want: []string{"東京", "tour"}},
+		{text: " -- ", want: []string{}},
+	}
+
+	for _, test := range tests {
+		got := tokenize(test.text)
+		if len(got) == 0 && len(test.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []searchClause
+	}{
+		{query: "Minecraft", want: []searchClause{{words: []string{"minecraft"}}}},
+		{query: "mine*", want: []searchClause{{words: []string{"mine"}, prefix: true}}},
+		{query: "Café*", want: []searchClause{{words: []string{"cafe"}, prefix: true}}},
+		{
+			// Only the last word of a split field is a prefix.
+			query: "hard-core*",
+			want: []searchClause{
+				{words: []string{"hard"}},
+				{words: []string{"core"}, prefix: true},
+			},
+		},
+		{
+			query: `"Let's Play" minecraft`,
+			want: []searchClause{
+				{words: []string{"lets", "play"}},
+				{words: []string{"minecraft"}},
+			},
+		},
+		{
+			// A star inside a phrase is not a prefix.
+			query: `"let's pla*"`,
+			want:  []searchClause{{words: []string{"lets", "pla"}}},
+		},
+		{
+			// An unclosed quote runs to the end of the query.
+			query: `build "red stone`,
+			want: []searchClause{
+				{words: []string{"build"}},
+				{words: []string{"red", "stone"}},
+			},
+		},
+		{query: `"" * -`, want: []searchClause{}},
+	}
+
+	for _, test := range tests {
+		got := parseSearchQuery(test.query)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseSearchQuery(%q) = %+v, want %+v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchChannelRanking(t *testing.T) {
+	youtube := NewYouTubeService(Options{})
+	useChannelIndex(youtube, "UCchannel",
+		&VideoDetails{VideoId: "description", Title: "Building a house", Description: "Built in Minecraft."},
+		&VideoDetails{VideoId: "title", Title: "Minecraft: Let's Play"},
+		&VideoDetails{VideoId: "tags", Title: "Episode 3", Tags: []string{"minecraft", "let's"}},
+		&VideoDetails{VideoId: "tie", Title: "Minecraft: Let's Play"},
+		&VideoDetails{VideoId: "minecart", Title: "Minecart rails", Tags: []string{"play"}},
+		&VideoDetails{VideoId: "undermine", Title: "Undermine the mind"},
+		&VideoDetails{VideoId: "reversed", Title: "Play, let's"},
+	)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Title hits outrank tag hits, which outrank description hits. Equal
+		// scores keep timeline order.
+		{query: "minecraft", want: []string{"title", "tie", "tags", "description"}},
+		{query: "MINECRAFT", want: []string{"title", "tie", "tags", "description"}},
+		// A prefix matches every term that starts with it, and no other.
+		{query: "mine*", want: []string{"title", "tie", "minecart", "tags", "description"}},
+		{query: "mind*", want: []string{"undermine"}},
+		{query: "min", want: []string{}},
+		// A phrase needs its words in order, in one field and one tag.
+		{query: `"let's play"`, want: []string{"title", "tie"}},
+		{query: `"lets minecraft"`, want: []string{}},
+		// Every clause has to match.
+		{query: "minecraft building", want: []string{"description"}},
+		{query: "minecraft rails", want: []string{}},
+	}
+
+	for _, test := range tests {
+		results, err := youtube.SearchChannel("UCchannel", test.query, 0)
+		if err != nil {
+			t.Errorf("SearchChannel(%q) error = %s", test.query, err)
+			continue
+		}
+
+		got := make([]string, len(results.Results))
+		for i, result := range results.Results {
+			got[i] = result.VideoId
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SearchChannel(%q) = %q, want %q", test.query, got, test.want)
+		}
+	}
+
+	if _, err := youtube.SearchChannel("UCchannel", `"" *`, 0); err == nil {
+		t.Error("SearchChannel with no searchable terms succeeded")
+	}
+
+	results, _ := youtube.SearchChannel("UCchannel", "minecraft", 2)
+	if results.TotalResults != 4 || results.Count != 2 {
+		t.Errorf("limited to 2: total %d, count %d", results.TotalResults, results.Count)
+	}
+}
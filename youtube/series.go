@@ -0,0 +1,232 @@
+package youtube
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrVideoNotInSeries error = &classifiedError{ErrNotFound, "video is not part of a detected series"}
+
+const seriesSeparators = " \t-–—:|,.([{"
+
+// Each pattern captures the series title, the episode marker and the episode
+// number, as in "Let's Play X – Part 14", "Ep. 203: Guest" or "Vlog #57".
+var seriesPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(.*?)\b(part|pt\.?|episode|ep\.?|folge|teil|chapter|day)\s*#?\s*(\d+)\b`),
+	regexp.MustCompile(`^(.*?)(#)(\d+)\b`),
+}
+
+// seasonPattern matches "S2E5", which is read as "Season 2 Episode 5" so that
+// each season is a series of its own.
+var seasonPattern = regexp.MustCompile(`(?i)^(.*?)\bs(\d+)\s*e(\d+)\b`)
+
+var seriesMarkers = map[string]string{
+	"part":    "Part",
+	"pt":      "Part",
+	"teil":    "Part",
+	"episode": "Episode",
+	"ep":      "Episode",
+	"folge":   "Episode",
+	"chapter": "Chapter",
+	"day":     "Day",
+	"#":       "#",
+}
+
+type Series struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	EpisodeCount int    `json:"episodeCount"`
+	FirstEpisode int    `json:"firstEpisode"`
+	LastEpisode  int    `json:"lastEpisode"`
+}
+
+type SeriesEpisode struct {
+	VideoId     string    `json:"videoId"`
+	Title       string    `json:"title"`
+	Episode     int       `json:"episode"`
+	Position    int       `json:"position"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+type VideoSeries struct {
+	Series   *Series        `json:"series"`
+	Episode  *SeriesEpisode `json:"episode"`
+	Previous *SeriesEpisode `json:"previous"`
+	Next     *SeriesEpisode `json:"next"`
+}
+
+type seriesIndex struct {
+	series   []*Series
+	episodes map[string][]*SeriesEpisode
+	videos   map[string]string
+}
+
+func detectSeries(title string) (id string, name string, episode int, ok bool) {
+	if match := seasonPattern.FindStringSubmatch(title); match != nil {
+		if season, err := strconv.Atoi(match[2]); err == nil {
+			title = strings.TrimSpace(fmt.Sprintf(
+				"%s Season %d Episode %s",
+				strings.TrimRight(match[1], seriesSeparators), season, match[3],
+			))
+		}
+	}
+
+	for _, pattern := range seriesPatterns {
+		match := pattern.FindStringSubmatch(title)
+		if match == nil {
+			continue
+		}
+
+		episode, err := strconv.Atoi(match[3])
+		if err != nil {
+			continue
+		}
+
+		marker := seriesMarkers[strings.TrimSuffix(strings.ToLower(match[2]), ".")]
+		name := strings.TrimRight(match[1], seriesSeparators)
+
+		words := tokenize(name)
+		if len(words) == 0 {
+			// "Ep. 203" with no title is the channel's own numbered show.
+			name = marker
+		}
+		id := strings.Join(append(words, tokenize(marker)...), "-")
+		if marker == "#" {
+			id = strings.Join(append(words, "number"), "-")
+		}
+
+		return id, name, episode, true
+	}
+
+	return "", "", 0, false
+}
+
+func buildSeriesIndex(
+	videos []PlaylistVideo, details map[string]*VideoDetails,
+) *seriesIndex {
+	index := &seriesIndex{
+		series:   []*Series{},
+		episodes: make(map[string][]*SeriesEpisode),
+		videos:   make(map[string]string),
+	}
+
+	names := make(map[string]string)
+	order := []string{}
+
+	for position, video := range videos {
+		videoDetails, ok := details[video.VideoId]
+		if !ok {
+			continue
+		}
+
+		id, name, episode, ok := detectSeries(videoDetails.Title)
+		if !ok {
+			continue
+		}
+
+		if _, ok := names[id]; !ok {
+			names[id] = name
+			order = append(order, id)
+		}
+
+		// Timestamps were validated when the timeline was sorted.
+		publishedAt, _ := parseTimestamp(video.PublishedAt)
+
+		index.episodes[id] = append(index.episodes[id], &SeriesEpisode{
+			VideoId:     video.VideoId,
+			Title:       videoDetails.Title,
+			Episode:     episode,
+			Position:    position,
+			PublishedAt: publishedAt,
+		})
+	}
+
+	for _, id := range order {
+		episodes := index.episodes[id]
+
+		// A single numbered upload is not a series.
+		if len(episodes) < 2 {
+			delete(index.episodes, id)
+			continue
+		}
+
+		sort.SliceStable(episodes, func(i, j int) bool {
+			if episodes[i].Episode != episodes[j].Episode {
+				return episodes[i].Episode < episodes[j].Episode
+			}
+			return episodes[i].Position < episodes[j].Position
+		})
+
+		index.series = append(index.series, &Series{
+			Id:           id,
+			Name:         names[id],
+			EpisodeCount: len(episodes),
+			FirstEpisode: episodes[0].Episode,
+			LastEpisode:  episodes[len(episodes)-1].Episode,
+		})
+
+		for _, episode := range episodes {
+			index.videos[episode.VideoId] = id
+		}
+	}
+
+	return index
+}
+
+func (youtube *YouTube) GetChannelSeries(channelId string) ([]*Series, error) {
+	index, err := youtube.getChannelIndex(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	return index.seriesIndex().series, nil
+}
+
+func (youtube *YouTube) GetVideoSeries(
+	channelId string, videoId string,
+) (*VideoSeries, error) {
+	index, err := youtube.getChannelIndex(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if index.position(videoId) == -1 {
+		return nil, notFoundf("video not found in uploads playlist")
+	}
+
+	series := index.seriesIndex()
+
+	id, ok := series.videos[videoId]
+	if !ok {
+		return nil, ErrVideoNotInSeries
+	}
+
+	videoSeries := &VideoSeries{}
+	for _, candidate := range series.series {
+		if candidate.Id == id {
+			videoSeries.Series = candidate
+			break
+		}
+	}
+
+	episodes := series.episodes[id]
+	for i, episode := range episodes {
+		if episode.VideoId != videoId {
+			continue
+		}
+		videoSeries.Episode = episode
+		if i > 0 {
+			videoSeries.Previous = episodes[i-1]
+		}
+		if i < len(episodes)-1 {
+			videoSeries.Next = episodes[i+1]
+		}
+		break
+	}
+
+	return videoSeries, nil
+}
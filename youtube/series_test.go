@@ -0,0 +1,122 @@
+package youtube
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectSeries(t *testing.T) {
+	tests := []struct {
+		title   string
+		id      string
+		name    string
+		episode int
+	}{
+		{title: "Ep. 12: Guest", id: "episode", name: "Episode", episode: 12},
+		{title: "Podcast Ep 7", id: "podcast-episode", name: "Podcast", episode: 7},
+		{title: "Let's Play Zelda – Part 3", id: "lets-play-zelda-part", name: "Let's Play Zelda", episode: 3},
+		{title: "Zelda pt. 14 (finale)", id: "zelda-part", name: "Zelda", episode: 14},
+		{title: "Vlog #4", id: "vlog-number", name: "Vlog", episode: 4},
+		{title: "#57 - Morning routine", id: "number", name: "#", episode: 57},
+		{title: "Chapter 2", id: "chapter", name: "Chapter", episode: 2},
+		{title: "Survival S2E5: The Nether", id: "survival-season-2-episode", name: "Survival Season 2", episode: 5},
+		{title: "Survival s02 e06", id: "survival-season-2-episode", name: "Survival Season 2", episode: 6},
+		{title: "Survival Season 2 Episode 7", id: "survival-season-2-episode", name: "Survival Season 2", episode: 7},
+		{title: "S3E1", id: "season-3-episode", name: "Season 3", episode: 1},
+		{title: "Folge 9", id: "episode", name: "Episode", episode: 9},
+
+		// Not episodes.
+		{title: "Top 10 moments"},
+		{title: "Departure 3"},
+		{title: "Party 2 recap"},
+		{title: "Epic 5"},
+		{title: "Day in the life"},
+		{title: "Oh my S2"},
+		{title: "MS2E5 launch"},
+		{title: ""},
+	}
+
+	for _, test := range tests {
+		id, name, episode, ok := detectSeries(test.title)
+		if ok != (test.id != "") {
+			t.Errorf("detectSeries(%q) ok = %v", test.title, ok)
+			continue
+		}
+		if id != test.id || name != test.name || episode != test.episode {
+			t.Errorf(
+				"detectSeries(%q) = %q, %q, %d, want %q, %q, %d",
+				test.title, id, name, episode, test.id, test.name, test.episode,
+			)
+		}
+	}
+}
+
+func TestGetVideoSeries(t *testing.T) {
+	youtube := NewYouTubeService(Options{})
+	useChannelIndex(youtube, "UCchannel",
+		&VideoDetails{VideoId: "part2", Title: "Zelda Part 2"},
+		&VideoDetails{VideoId: "part1", Title: "Zelda Part 1"},
+		&VideoDetails{VideoId: "other", Title: "Q&A"},
+		&VideoDetails{VideoId: "part4", Title: "Zelda Part 4"},
+		&VideoDetails{VideoId: "reupload2", Title: "Zelda Part 2 (re-upload)"},
+		&VideoDetails{VideoId: "lone", Title: "Mario Part 1"},
+	)
+
+	tests := []struct {
+		videoId  string
+		previous string
+		next     string
+	}{
+		// Episodes are ordered by number, then by upload.
+		{videoId: "part1", next: "part2"},
+		{videoId: "part2", previous: "part1", next: "reupload2"},
+		{videoId: "reupload2", previous: "part2", next: "part4"},
+		{videoId: "part4", previous: "reupload2"},
+	}
+
+	for _, test := range tests {
+		series, err := youtube.GetVideoSeries("UCchannel", test.videoId)
+		if err != nil {
+			t.Errorf("GetVideoSeries(%s) error = %s", test.videoId, err)
+			continue
+		}
+		if series.Series.Id != "zelda-part" || series.Episode.VideoId != test.videoId {
+			t.Errorf("GetVideoSeries(%s) = series %s, episode %s", test.videoId, series.Series.Id, series.Episode.VideoId)
+		}
+
+		previous, next := "", ""
+		if series.Previous != nil {
+			previous = series.Previous.VideoId
+		}
+		if series.Next != nil {
+			next = series.Next.VideoId
+		}
+		if previous != test.previous || next != test.next {
+			t.Errorf(
+				"GetVideoSeries(%s) previous, next = %q, %q, want %q, %q",
+				test.videoId, previous, next, test.previous, test.next,
+			)
+		}
+	}
+
+	// A single numbered upload is not a series.
+	for _, videoId := range []string{"other", "lone"} {
+		if _, err := youtube.GetVideoSeries("UCchannel", videoId); !errors.Is(err, ErrVideoNotInSeries) {
+			t.Errorf("GetVideoSeries(%s) error = %v, want ErrVideoNotInSeries", videoId, err)
+		}
+	}
+	if _, err := youtube.GetVideoSeries("UCchannel", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetVideoSeries(missing) error = %v, want ErrNotFound", err)
+	}
+
+	series, err := youtube.GetChannelSeries("UCchannel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 {
+		t.Fatalf("GetChannelSeries = %d series, want 1", len(series))
+	}
+	if got := *series[0]; got != (Series{Id: "zelda-part", Name: "Zelda", EpisodeCount: 4, FirstEpisode: 1, LastEpisode: 4}) {
+		t.Errorf("GetChannelSeries = %+v", got)
+	}
+}
@@ -0,0 +1,133 @@
+package youtube
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+type SortKey string
+
+const (
+	SortKeyVideoPublishedAt SortKey = "videoPublishedAt"
+	SortKeyPublishedAt      SortKey = "publishedAt"
+	SortKeyActualStartTime  SortKey = "actualStartTime"
+	SortKeyRecordingDate    SortKey = "recordingDate"
+)
+
+func ParseSortKey(value string) (SortKey, error) {
+	switch SortKey(value) {
+	case "":
+		return SortKeyVideoPublishedAt, nil
+	case SortKeyVideoPublishedAt, SortKeyPublishedAt,
+		SortKeyActualStartTime, SortKeyRecordingDate:
+		return SortKey(value), nil
+	}
+
+	return "", invalidf(
+		"unknown sort key %s. Expected one of videoPublishedAt, publishedAt, actualStartTime, recordingDate",
+		value,
+	)
+}
+
+func ParseSortOrder(value string) (bool, error) {
+	switch value {
+	case "", "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	}
+
+	return false, invalidf("unknown sort order %s. Expected asc or desc", value)
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, invalidf("invalid RFC 3339 timestamp %q", value)
+	}
+
+	return timestamp, nil
+}
+
+// Videos that were never live or have no recording date fall back to the
+// time they were added to the uploads playlist, as do videos with no details,
+// such as ones made private since they were uploaded.
+func (key SortKey) timestamp(
+	video PlaylistVideo, details *VideoDetails,
+) string {
+	if details == nil {
+		return video.PublishedAt
+	}
+
+	switch key {
+	case SortKeyPublishedAt:
+		return details.PublishedAt
+	case SortKeyActualStartTime:
+		if details.ActualStartTime != "" {
+			return details.ActualStartTime
+		}
+	case SortKeyRecordingDate:
+		if details.RecordingDate != "" {
+			return details.RecordingDate
+		}
+	}
+
+	return video.PublishedAt
+}
+
+func (youtube *YouTube) sortVideos(
+	videos []PlaylistVideo, key SortKey, descending bool,
+) error {
+	var details map[string]*VideoDetails
+
+	if key != "" && key != SortKeyVideoPublishedAt {
+		videoIds := make([]string, len(videos))
+		for i, video := range videos {
+			videoIds[i] = video.VideoId
+		}
+
+		var err error
+		details, err = youtube.GetVideoDetails(videoIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	times := make(map[string]time.Time, len(videos))
+
+	for _, video := range videos {
+		timestamp := video.PublishedAt
+		if details != nil {
+			timestamp = key.timestamp(video, details[video.VideoId])
+		}
+
+		parsed, err := parseTimestamp(timestamp)
+		if err != nil {
+			return fmt.Errorf(
+				"cannot sort video %s by %s: %w", video.VideoId, key, err,
+			)
+		}
+		times[video.VideoId] = parsed
+	}
+
+	slices.SortFunc(videos,
+		func(a, b PlaylistVideo) int {
+			cmp := times[a.VideoId].Compare(times[b.VideoId])
+			if cmp == 0 {
+				if a.VideoId < b.VideoId {
+					cmp = -1
+				} else if a.VideoId > b.VideoId {
+					cmp = 1
+				}
+			}
+			if descending {
+				return -cmp
+			}
+			return cmp
+		},
+	)
+
+	return nil
+}
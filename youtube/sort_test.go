@@ -0,0 +1,100 @@
+package youtube
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useVideoDetails caches details, so looking them up needs no API calls.
+func useVideoDetails(youtube *YouTube, details ...*VideoDetails) {
+	youtube.videoDetailsMu.Lock()
+	defer youtube.videoDetailsMu.Unlock()
+	for _, video := range details {
+		video.fetchedAt = time.Now()
+		youtube.videoDetails[video.VideoId] = video
+	}
+}
+
+func videoIds(videos []PlaylistVideo) []string {
+	ids := make([]string, len(videos))
+	for i, video := range videos {
+		ids[i] = video.VideoId
+	}
+	return ids
+}
+
+func TestSortVideos(t *testing.T) {
+	// Videos the API has no details for, such as ones made private since,
+	// are answered with no items.
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, http.StatusOK, `{"items": []}`), nil
+	})
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+	useVideoDetails(youtube,
+		&VideoDetails{VideoId: "b", PublishedAt: "2020-01-01T00:00:00Z", RecordingDate: "2019-06-01T00:00:00Z"},
+		&VideoDetails{VideoId: "a", PublishedAt: "2020-01-01T00:00:00Z"},
+		&VideoDetails{VideoId: "c", PublishedAt: "2019-12-31T23:00:00-02:00", ActualStartTime: "2019-12-31T20:00:00Z"},
+	)
+
+	timeline := func() []PlaylistVideo {
+		return []PlaylistVideo{
+			{VideoId: "c", PublishedAt: "2020-01-02T00:00:00Z"},
+			{VideoId: "private", PublishedAt: "2019-12-31T00:00:00Z"},
+			{VideoId: "b", PublishedAt: "2020-01-01T00:00:00Z"},
+			{VideoId: "a", PublishedAt: "2020-01-01T00:00:00Z"},
+		}
+	}
+
+	tests := []struct {
+		key        SortKey
+		descending bool
+		want       []string
+	}{
+		// Equal timestamps are ordered by video ID, whichever way the sort runs.
+		{key: SortKeyVideoPublishedAt, want: []string{"private", "a", "b", "c"}},
+		{key: SortKeyVideoPublishedAt, descending: true, want: []string{"c", "b", "a", "private"}},
+		{key: "", want: []string{"private", "a", "b", "c"}},
+		// c was published at 01:00 UTC on January 1st.
+		{key: SortKeyPublishedAt, want: []string{"private", "a", "b", "c"}},
+		{key: SortKeyActualStartTime, want: []string{"private", "c", "a", "b"}},
+		{key: SortKeyRecordingDate, want: []string{"b", "private", "a", "c"}},
+		{key: SortKeyRecordingDate, descending: true, want: []string{"c", "a", "private", "b"}},
+	}
+
+	for _, test := range tests {
+		videos := timeline()
+		if err := youtube.sortVideos(videos, test.key, test.descending); err != nil {
+			t.Errorf("sortVideos by %q error = %s", test.key, err)
+			continue
+		}
+		if got := videoIds(videos); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("sortVideos by %q, descending %v = %q, want %q", test.key, test.descending, got, test.want)
+		}
+	}
+}
+
+func TestSortVideosRejectsInvalidTimestamps(t *testing.T) {
+	youtube := NewYouTubeService(Options{})
+
+	for _, timestamp := range []string{
+		"",
+		"2020-01-01",
+		"2020-01-01 00:00:00",
+		"2020-01-01T00:00:00",
+		"01/01/2020",
+		"1577836800",
+	} {
+		videos := []PlaylistVideo{
+			{VideoId: "good", PublishedAt: "2020-01-01T00:00:00Z"},
+			{VideoId: "bad", PublishedAt: timestamp},
+		}
+		err := youtube.sortVideos(videos, SortKeyVideoPublishedAt, false)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("sortVideos with timestamp %q error = %v, want ErrInvalidArgument", timestamp, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package youtube
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseCount reads a count the Data API returns as a string.
+func ParseCount(value string) (int64, error) {
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid count %q", value)
+	}
+	return count, nil
+}
+
+// ParseOptionalCount is ParseCount for counts a channel may hide, such as
+// likes, comments and subscribers, which are left out when hidden. A hidden
+// count is nil rather than 0.
+func ParseOptionalCount(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	count, err := ParseCount(value)
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+type VideoStatistics struct {
+	VideoId      string
+	ViewCount    string
+	LikeCount    string
+	CommentCount string
+}
+
+// Statistics change constantly, so unlike GetVideoDetails nothing is cached.
+func (youtube *YouTube) GetVideoStatistics(
+	videoIds []string,
+) (map[string]*VideoStatistics, error) {
+	const endpoint = "videos/"
+
+	statistics := make(map[string]*VideoStatistics, len(videoIds))
+
+	for start := 0; start < len(videoIds); start += videoDetailsBatchSize {
+		end := start + videoDetailsBatchSize
+		if end > len(videoIds) {
+			end = len(videoIds)
+		}
+
+		q := url.Values{}
+		q.Set("part", "statistics")
+		q.Set("id", strings.Join(videoIds[start:end], ","))
+		q.Set("maxResults", fmt.Sprint(end-start))
+
+		body, err := youtube.getJson(endpoint, q)
+		if err != nil {
+			return nil, err
+		}
+
+		items, ok := body["items"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf(
+				"error in YouTube Data API response from %s. Key 'items' not found",
+				endpoint,
+			)
+		}
+
+		for i := 0; i < len(items); i++ {
+			item, ok := items[i].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Cannot access items[%d]",
+					endpoint,
+					i,
+				)
+			}
+
+			videoId, ok := item["id"].(string)
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Key items[%d]['id'] not found",
+					endpoint,
+					i,
+				)
+			}
+
+			videoStatistics, ok := item["statistics"].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Key items[%d]['statistics'] not found",
+					endpoint,
+					i,
+				)
+			}
+
+			viewCount, ok := videoStatistics["viewCount"].(string)
+			if !ok {
+				return nil, fmt.Errorf(
+					"error in YouTube Data API response from %s. Key items[%d]['statistics']['viewCount'] not found",
+					endpoint,
+					i,
+				)
+			}
+
+			likeCount, _ := videoStatistics["likeCount"].(string)
+			commentCount, _ := videoStatistics["commentCount"].(string)
+
+			statistics[videoId] = &VideoStatistics{
+				VideoId:      videoId,
+				ViewCount:    viewCount,
+				LikeCount:    likeCount,
+				CommentCount: commentCount,
+			}
+		}
+	}
+
+	return statistics, nil
+}
@@ -0,0 +1,39 @@
+package youtube
+
+import "testing"
+
+func TestParseOptionalCount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    *int64
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "0", want: new(int64)},
+		{value: "12345", want: func() *int64 { n := int64(12345); return &n }()},
+		{value: "1.5k", wantErr: true},
+		{value: "-", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseOptionalCount(test.value)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseOptionalCount(%q) error = %v, want error %v", test.value, err, test.wantErr)
+			continue
+		}
+		switch {
+		case test.wantErr:
+		case got == nil || test.want == nil:
+			if got != test.want {
+				t.Errorf("ParseOptionalCount(%q) = %v, want %v", test.value, got, test.want)
+			}
+		case *got != *test.want:
+			t.Errorf("ParseOptionalCount(%q) = %d, want %d", test.value, *got, *test.want)
+		}
+	}
+
+	// A required count may not be left out.
+	if _, err := ParseCount(""); err == nil {
+		t.Error(`ParseCount("") succeeded`)
+	}
+}
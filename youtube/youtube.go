@@ -1,21 +1,64 @@
 package youtube
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"net/url"
-	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"golang.org/x/exp/slices"
 )
 
 const BaseUrl = "https://www.googleapis.com/youtube/v3/"
 
+const DefaultWindowRadius = 10
+
+const MaxWindowRadius = 50
+
+type Options struct {
+	// ApiKeys are used in turn, spreading requests over their quotas.
+	ApiKeys          []string
+	WindowRadius     int
+	PlaylistIndexTTL time.Duration
+	VideoDetailsTTL  time.Duration
+	ChannelIndexTTL  time.Duration
+}
+
+// client holds the state shared by every YouTube handle.
+type client struct {
+	apiKeys []string
+	nextKey uint32
+
+	windowRadius     int
+	playlistIndexTTL time.Duration
+	videoDetailsTTL  time.Duration
+	channelIndexTTL  time.Duration
+
+	playlistIndexesMu   sync.Mutex
+	playlistIndexes     map[string]*playlistIndex
+	playlistIndexBuilds map[string]*playlistIndexBuild
+
+	videoDetailsMu sync.Mutex
+	videoDetails   map[string]*VideoDetails
+
+	channelIndexesMu sync.Mutex
+	channelIndexes   map[string]*channelIndex
+
+	keyStatesMu sync.Mutex
+	keyStates   map[string]*keyState
+}
+
+// A YouTube is a handle on the shared client bound to a context. The context's
+// request ID tags upstream calls, and cancelling it aborts them.
 type YouTube struct {
-	apiKey string
+	*client
+	ctx context.Context
+
+	// pages, when set, counts the playlist pages fetched through this handle.
+	pages *atomic.Int64
 }
 
 type VideoMetadata struct {
@@ -23,7 +66,13 @@ type VideoMetadata struct {
 	VideoTitle       string
 	VideoThumbnail   string
 	ViewCount        string
+	LikeCount        string
+	CommentCount     string
 	PublishedAt      string
+	Duration         int
+	Definition       string
+	Caption          bool
+	LicensedContent  bool
 	ChannelTitle     string
 	ChannelId        string
 	ChannelThumbnail string
@@ -32,33 +81,110 @@ type VideoMetadata struct {
 	VideoCount       string
 }
 
+type Channel struct {
+	ChannelId        string
+	ChannelTitle     string
+	ChannelThumbnail string
+	ChannelCustomUrl string
+	// SubscriberCount is empty when the channel hides it.
+	SubscriberCount string
+	VideoCount      string
+	ViewCount       string
+}
+
 type VideoList struct {
-	Count  int
-	Videos []*VideoMetadata
+	Count          int
+	Position       int
+	TotalResults   int
+	PreviousAnchor string
+	NextAnchor     string
+	Videos         []*VideoMetadata
 }
 
 type ChannelPlaylist struct {
-	Id        string
-	Title     string
-	Thumbnail string
+	Id         string
+	Title      string
+	Thumbnail  string
+	VideoCount int
 }
 
 type ChannelPlaylists struct {
-	Count     int
-	Playlists []*ChannelPlaylist
+	Count         int
+	TotalResults  int
+	NextPageToken string
+	PrevPageToken string
+	Playlists     []*ChannelPlaylist
 }
 
 type PlaylistVideo struct {
 	VideoId     string
+	ChannelId   string
 	PublishedAt string
 }
 
-func NewYouTubeService(apiKey string) *YouTube {
-	youtubeService := YouTube{
-		apiKey: apiKey,
+type ChannelVideosOptions struct {
+	ContentType ContentType
+	MinDuration int
+	MaxDuration int
+	SortKey     SortKey
+	Descending  bool
+}
+
+func NewYouTubeService(options Options) *YouTube {
+	youtubeService := client{
+		apiKeys:             options.ApiKeys,
+		windowRadius:        options.WindowRadius,
+		playlistIndexTTL:    options.PlaylistIndexTTL,
+		videoDetailsTTL:     options.VideoDetailsTTL,
+		channelIndexTTL:     options.ChannelIndexTTL,
+		playlistIndexes:     make(map[string]*playlistIndex),
+		playlistIndexBuilds: make(map[string]*playlistIndexBuild),
+		videoDetails:        make(map[string]*VideoDetails),
+		channelIndexes:      make(map[string]*channelIndex),
+		keyStates:           make(map[string]*keyState),
 	}
 
-	return &youtubeService
+	if youtubeService.windowRadius <= 0 {
+		youtubeService.windowRadius = DefaultWindowRadius
+	}
+	if youtubeService.playlistIndexTTL <= 0 {
+		youtubeService.playlistIndexTTL = PlaylistIndexTTL
+	}
+	if youtubeService.videoDetailsTTL <= 0 {
+		youtubeService.videoDetailsTTL = VideoDetailsTTL
+	}
+	if youtubeService.channelIndexTTL <= 0 {
+		youtubeService.channelIndexTTL = ChannelIndexTTL
+	}
+
+	return &YouTube{client: &youtubeService, ctx: context.Background()}
+}
+
+func (youtube *YouTube) WithContext(ctx context.Context) *YouTube {
+	return &YouTube{client: youtube.client, ctx: ctx}
+}
+
+// apiKey returns the keys in turn, passing over those out of quota until
+// their quota resets. When every key is out, the next one is used anyway, so
+// the call fails with the quota error.
+func (youtube *client) apiKey() string {
+	if len(youtube.apiKeys) == 0 {
+		return ""
+	}
+	count := uint32(len(youtube.apiKeys))
+	next := atomic.AddUint32(&youtube.nextKey, 1) - 1
+	now := time.Now()
+
+	youtube.keyStatesMu.Lock()
+	defer youtube.keyStatesMu.Unlock()
+
+	for i := uint32(0); i < count; i++ {
+		key := youtube.apiKeys[(next+i)%count]
+		if state, ok := youtube.keyStates[key]; !ok || !now.Before(state.exhaustedUntil) {
+			return key
+		}
+	}
+	return youtube.apiKeys[next%count]
 }
 
 func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error) {
@@ -66,9 +192,10 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	properties := []string{
 		"snippet",
 		"statistics",
+		"contentDetails",
 	}
 
-	id, err := parseVideoId(idOrUrl)
+	id, err := ParseVideoId(idOrUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -79,22 +206,18 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	}
 
 	q := url.Query()
-	q.Set("key", youtube.apiKey)
+	q.Set("key", youtube.apiKey())
 	q.Set("part", strings.Join(properties, ","))
 	q.Set("id", id)
 	q.Set("maxResults", "1")
 	url.RawQuery = q.Encode()
 
-	res, err := http.Get(url.String())
+	res, err := youtube.get(endpoint, url)
 	if err != nil {
 		return nil, err
 	}
 	if res.StatusCode != 200 {
-		return nil, fmt.Errorf(
-			"call to YouTube API %s endpoint failed with message %s",
-			endpoint,
-			res.Status,
-		)
+		return nil, newUpstreamError(endpoint, res)
 	}
 
 	defer res.Body.Close()
@@ -107,18 +230,18 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'items' not found",
-			url.String(),
+			endpoint,
 		)
 	}
 
 	if len(items) == 0 {
-		return nil, fmt.Errorf("no results found for %s", idOrUrl)
+		return nil, notFoundf("no results found for %s", idOrUrl)
 	}
 
 	item, ok := items[0].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
-			"error in YouTube Data API response from %s", url.String(),
+			"error in YouTube Data API response from %s", endpoint,
 		)
 	}
 
@@ -126,7 +249,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'id' not found in items[0]",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -134,7 +257,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'snippet' not found in items[0]",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -142,7 +265,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'publishedAt' not found in items[0]['snippet']",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -150,7 +273,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'title' not found in items[0]['snippet']",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -158,7 +281,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'thumbnails' not found in items[0]['snippet']",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -166,7 +289,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'standard' not found in items[0]['snippet']['thumbnails'e",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -174,7 +297,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'url' not found in items[0]['snippet']['thumbnails']['standard']",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -182,7 +305,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'channelId' not found in items[0]['snippet']",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -190,7 +313,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response %s. Key 'channelTitle' not found in items[0]['snippet']",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -198,7 +321,7 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'statistics' not found in items[0]",
-			url.String(),
+			endpoint,
 		)
 	}
 
@@ -206,68 +329,115 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'viewCount' not found in items[0]['statistics']",
-			url.String(),
+			endpoint,
+		)
+	}
+
+	// Likes and comments are absent when hidden or disabled by the uploader.
+	likeCount, _ := statistics["likeCount"].(string)
+	commentCount, _ := statistics["commentCount"].(string)
+
+	contentDetails, ok := item["contentDetails"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf(
+			"error in YouTube Data API response from %s. Key 'contentDetails' not found in items[0]",
+			endpoint,
+		)
+	}
+
+	details := VideoDetails{VideoId: videoId}
+	err = parseContentDetails(contentDetails, &details)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"error in YouTube Data API response from %s: %w",
+			endpoint,
+			err,
 		)
 	}
 
+	channel, err := youtube.GetChannel(channelId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VideoMetadata{
+		VideoId:          videoId,
+		VideoTitle:       title,
+		VideoThumbnail:   thumbnailUrl,
+		ViewCount:        viewCount,
+		LikeCount:        likeCount,
+		CommentCount:     commentCount,
+		PublishedAt:      publishedAt,
+		Duration:         details.Duration,
+		Definition:       details.Definition,
+		Caption:          details.Caption,
+		LicensedContent:  details.LicensedContent,
+		ChannelId:        channelId,
+		ChannelTitle:     channelTitle,
+		ChannelThumbnail: channel.ChannelThumbnail,
+		ChannelCustomUrl: channel.ChannelCustomUrl,
+		SubscriberCount:  channel.SubscriberCount,
+		VideoCount:       channel.VideoCount,
+	}, nil
+}
+
+func (youtube *YouTube) GetChannel(channelId string) (*Channel, error) {
 	const channelEndpoint = "channels/"
-	properties = []string{
+	properties := []string{
 		"snippet",
 		"statistics",
 	}
-	url, err = url.Parse(BaseUrl + channelEndpoint)
+	url, err := url.Parse(BaseUrl + channelEndpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	q = url.Query()
-	q.Set("key", youtube.apiKey)
+	q := url.Query()
+	q.Set("key", youtube.apiKey())
 	q.Set("part", strings.Join(properties, ","))
 	q.Set("id", channelId)
 	q.Set("maxResults", "1")
 	url.RawQuery = q.Encode()
 
-	res2, err := http.Get(url.String())
+	res, err := youtube.get(channelEndpoint, url)
 	if err != nil {
 		return nil, err
 	}
-	if res2.StatusCode != 200 {
-		return nil, fmt.Errorf(
-			"call to YouTube API %s endpoint failed with Status: %s", channelEndpoint, res.Status,
-		)
+	if res.StatusCode != 200 {
+		return nil, newUpstreamError(channelEndpoint, res)
 	}
 
-	body = make(map[string]interface{})
+	body := make(map[string]interface{})
 
-	json.NewDecoder(res2.Body).Decode(&body)
+	json.NewDecoder(res.Body).Decode(&body)
 
-	res2.Body.Close()
+	res.Body.Close()
 
-	items, ok = body["items"].([]interface{})
+	items, ok := body["items"].([]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'items' not found",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
 	if len(items) == 0 {
-		return nil, fmt.Errorf("no channels found for %s", channelId)
+		return nil, notFoundf("no channels found for %s", channelId)
 	}
 
-	item, ok = items[0].(map[string]interface{})
+	item, ok := items[0].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Unable to index 'items' list",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
-	snippet, ok = item["snippet"].(map[string]interface{})
+	snippet, ok := item["snippet"].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'snippet' not found in items[0]",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
@@ -275,23 +445,23 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'customUrl not found in items[0]['snippet']",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
-	thumbnails, ok = snippet["thumbnails"].(map[string]interface{})
+	thumbnails, ok := snippet["thumbnails"].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'thumbnails' not found in items[0]['snippet']",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
-	thumbnail, ok = thumbnails["medium"].(map[string]interface{})
+	thumbnail, ok := thumbnails["medium"].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'medium' not found in items[0]['snippet']['thumbnails']",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
@@ -299,56 +469,65 @@ func (youtube *YouTube) GetVideoMetadata(idOrUrl string) (*VideoMetadata, error)
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'url' not found in items[0]['snippet']['thumbnails']['medium']",
-			url.String(),
+			channelEndpoint,
 		)
 	}
 
-	statistics, ok = item["statistics"].(map[string]interface{})
+	statistics, ok := item["statistics"].(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf(
 			"error in YouTube Data API response from %s. Key 'statistics' not found in items[0]",
-			url.String(),
+			channelEndpoint,
+		)
+	}
+
+	// Channels may hide their subscriber count, which the API then leaves out
+	// or, for older responses, rounds to a meaningless value.
+	subscriberCount, _ := statistics["subscriberCount"].(string)
+	if hidden, _ := statistics["hiddenSubscriberCount"].(bool); hidden {
+		subscriberCount = ""
+	}
+
+	videoCount, ok := statistics["videoCount"].(string)
+	if !ok {
+		return nil, fmt.Errorf(
+			"error in YouTube Data API response from %s. Key 'videoCount' not found in items[0]['statistics']",
+			channelEndpoint,
 		)
 	}
 
-	subscriberCount, ok := statistics["subscriberCount"].(string)
+	viewCount, ok := statistics["viewCount"].(string)
 	if !ok {
 		return nil, fmt.Errorf(
-			"error in YouTube Data API response from %s. Key 'subscriberCount' not found in items[0]['statistics']",
-			url.String(),
+			"error in YouTube Data API response from %s. Key 'viewCount' not found in items[0]['statistics']",
+			channelEndpoint,
 		)
 	}
 
-	videoCount, ok := statistics["videoCount"].(string)
+	title, ok := snippet["title"].(string)
 	if !ok {
 		return nil, fmt.Errorf(
-			"error in YouTube Data API response from %s. Key 'videoCount' not found in items[0]['statistics']",
-			url.String(),
+			"error in YouTube Data API response from %s. Key 'title' not found in items[0]['snippet']",
+			channelEndpoint,
 		)
 	}
 
-	return &VideoMetadata{
-		VideoId:          videoId,
-		VideoTitle:       title,
-		VideoThumbnail:   thumbnailUrl,
-		ViewCount:        viewCount,
-		PublishedAt:      publishedAt,
+	return &Channel{
 		ChannelId:        channelId,
-		ChannelTitle:     channelTitle,
+		ChannelTitle:     title,
 		ChannelThumbnail: channelThumbnailUrl,
 		ChannelCustomUrl: customUrl,
 		SubscriberCount:  subscriberCount,
 		VideoCount:       videoCount,
+		ViewCount:        viewCount,
 	}, nil
 }
 
-func (youtube *YouTube) GetChannelVideos(
-	channelId string, videoId string,
-) (*VideoList, error) {
-	playlistId, err := youtube.GetUploadsPlaylist(channelId)
-	if err != nil {
-		return nil, err
-	}
+func (youtube *YouTube) GetPlaylistVideos(
+	playlistId string,
+) ([]PlaylistVideo, error) {
+	paginationJobs.With().Inc()
+	defer paginationJobs.With().Dec()
 
 	totalResults, err := youtube.GetPlaylistVideoCount(playlistId)
 	if err != nil {
@@ -364,13 +543,19 @@ func (youtube *YouTube) GetChannelVideos(
 
 	chPageTokens <- ""
 
+	// The crawl has a context of its own, cancelled on return, so that it
+	// stops fetching pages once the first error has been reported.
+	ctx, cancel := context.WithCancel(youtube.ctx)
+	defer cancel()
+	crawler := &YouTube{client: youtube.client, ctx: ctx, pages: youtube.pages}
+
 	stop := false
 
 	go func() {
-		for !stop {
+		for !stop && ctx.Err() == nil {
 			select {
 			case pageToken := <-chPageTokens:
-				youtube.GetPageVideos(
+				crawler.GetPageVideos(
 					playlistId,
 					pageToken,
 					chPageTokens,
@@ -381,7 +566,7 @@ func (youtube *YouTube) GetChannelVideos(
 			case <-chPageTokensDone:
 				select {
 				case pageToken := <-chPageTokens:
-					youtube.GetPageVideos(
+					crawler.GetPageVideos(
 						playlistId,
 						pageToken,
 						chPageTokens,
@@ -399,23 +584,111 @@ func (youtube *YouTube) GetChannelVideos(
 	}()
 
 	for i := 0; i < totalResults; i++ {
-		videos[i] = <-chVideos
-	}
-
-	slices.SortFunc(videos,
-		func(a, b PlaylistVideo) int {
-			timeFormat := "2006-01-02T15:04:05Z"
-			timeA, _ := time.Parse(timeFormat, a.PublishedAt)
-			timeB, _ := time.Parse(timeFormat, b.PublishedAt)
-			if timeA.After(timeB) {
-				return 1
-			} else if timeA.Before(timeB) {
-				return -1
-			} else {
-				return 0
+		select {
+		case videos[i] = <-chVideos:
+		case err := <-chPageTokensErrors:
+			return nil, err
+		case <-youtube.ctx.Done():
+			return nil, youtube.ctx.Err()
+		}
+	}
+
+	return videos, nil
+}
+
+func (youtube *YouTube) filterVideos(
+	videos []PlaylistVideo, keep func(*VideoDetails) bool,
+) ([]PlaylistVideo, error) {
+	videoIds := make([]string, len(videos))
+	for i, video := range videos {
+		videoIds[i] = video.VideoId
+	}
+
+	details, err := youtube.GetVideoDetails(videoIds)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []PlaylistVideo{}
+	for _, video := range videos {
+		videoDetails, ok := details[video.VideoId]
+		if ok && keep(videoDetails) {
+			filtered = append(filtered, video)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (youtube *YouTube) GetTimeline(
+	channelId string, options ChannelVideosOptions,
+) ([]PlaylistVideo, error) {
+	contentType := options.ContentType
+	if contentType == "" {
+		contentType = ContentTypeAll
+	}
+
+	playlistId, err := youtube.GetUploadsPlaylist(channelId, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Variant playlists are missing for some channels. Fall back to the full
+	// uploads playlist and classify each video ourselves.
+	classify := false
+
+	videos, err := youtube.GetPlaylistVideos(playlistId)
+	if errors.Is(err, ErrPlaylistNotFound) && contentType != ContentTypeAll {
+		playlistId, err = youtube.GetUploadsPlaylist(channelId, ContentTypeAll)
+		if err != nil {
+			return nil, err
+		}
+		videos, err = youtube.GetPlaylistVideos(playlistId)
+		classify = true
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if classify || options.MinDuration > 0 || options.MaxDuration > 0 {
+		videos, err = youtube.filterVideos(videos, func(details *VideoDetails) bool {
+			if classify && !contentType.matches(details) {
+				return false
+			}
+			if options.MinDuration > 0 && details.Duration < options.MinDuration {
+				return false
 			}
-		},
-	)
+			if options.MaxDuration > 0 && details.Duration > options.MaxDuration {
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range videos {
+		videos[i].ChannelId = channelId
+	}
+
+	err = youtube.sortVideos(videos, options.SortKey, options.Descending)
+	if err != nil {
+		return nil, err
+	}
+
+	return videos, nil
+}
+
+func (youtube *YouTube) GetChannelVideos(
+	channelId string, videoId string, options ChannelVideosOptions,
+) (*VideoList, error) {
+	counted := &YouTube{client: youtube.client, ctx: youtube.ctx, pages: &atomic.Int64{}}
+	videos, err := counted.GetTimeline(channelId, options)
+	channelVideosPages.With().Observe(float64(counted.pages.Load()))
+	if err != nil {
+		return nil, err
+	}
 
 	ind := -1
 
@@ -427,58 +700,87 @@ func (youtube *YouTube) GetChannelVideos(
 	}
 
 	if ind == -1 {
-		return nil, fmt.Errorf("video not found in uploads playlist")
+		return nil, notFoundf("video not found in uploads playlist matching the filters")
+	}
+
+	return youtube.getWindow(videos, ind), nil
+}
+
+func (youtube *YouTube) getWindow(videos []PlaylistVideo, ind int) *VideoList {
+
+	type windowVideo struct {
+		position int
+		metadata *VideoMetadata
 	}
 
-	chRequiredVideos := make(chan *VideoMetadata, 21)
+	windowRadius := youtube.windowRadius
+	windowSize := 2*windowRadius + 1
+
+	chRequiredVideos := make(chan windowVideo, windowSize)
 
-	for j := ind - 10; j <= ind+10; j++ {
+	for j := ind - windowRadius; j <= ind+windowRadius; j++ {
 		go func(j int) {
 			if j < len(videos) && j >= 0 {
 				data, err := youtube.GetVideoMetadata(videos[j].VideoId)
 				if err != nil {
-					fmt.Fprintf(
-						os.Stderr,
+					youtube.logger().Error(fmt.Sprintf(
 						"Error finding metadata for %s: %s",
 						videos[j].VideoId,
 						err,
-					)
-					chRequiredVideos <- nil
+					))
+					chRequiredVideos <- windowVideo{j, nil}
 				} else {
-					chRequiredVideos <- data
+					chRequiredVideos <- windowVideo{j, data}
 				}
 			} else {
-				chRequiredVideos <- nil
+				chRequiredVideos <- windowVideo{j, nil}
 			}
 		}(j)
 	}
 
-	requiredVideos := []*VideoMetadata{}
+	// Keep the timeline's order rather than re-sorting on snippet.publishedAt,
+	// which need not agree with the selected sort key.
+	window := make([]*VideoMetadata, windowSize)
 
-	for i := 0; i < 21; i++ {
+	for i := 0; i < windowSize; i++ {
 		video := <-chRequiredVideos
+		window[video.position-(ind-windowRadius)] = video.metadata
+	}
+
+	requiredVideos := []*VideoMetadata{}
+
+	for _, video := range window {
 		if video != nil {
 			requiredVideos = append(requiredVideos, video)
 		}
 	}
 
-	slices.SortFunc(requiredVideos,
-		func(a, b *VideoMetadata) int {
-			timeFormat := "2006-01-02T15:04:05Z"
-			timeA, _ := time.Parse(timeFormat, a.PublishedAt)
-			timeB, _ := time.Parse(timeFormat, b.PublishedAt)
-			if timeA.After(timeB) {
-				return 1
-			} else if timeA.Before(timeB) {
-				return -1
-			} else {
-				return 0
-			}
-		},
-	)
+	// The adjacent windows are centred one full window away, clamped to the
+	// ends of the timeline.
+	previousAnchor := ""
+	if ind-windowRadius > 0 {
+		previous := ind - windowSize
+		if previous < 0 {
+			previous = 0
+		}
+		previousAnchor = videos[previous].VideoId
+	}
+
+	nextAnchor := ""
+	if ind+windowRadius < len(videos)-1 {
+		next := ind + windowSize
+		if next > len(videos)-1 {
+			next = len(videos) - 1
+		}
+		nextAnchor = videos[next].VideoId
+	}
 
 	return &VideoList{
-		Count:  len(requiredVideos),
-		Videos: requiredVideos,
-	}, nil
+		Count:          len(requiredVideos),
+		Position:       ind,
+		TotalResults:   len(videos),
+		PreviousAnchor: previousAnchor,
+		NextAnchor:     nextAnchor,
+		Videos:         requiredVideos,
+	}
 }
@@ -0,0 +1,226 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// useTransport answers the Data API calls of a test with fn.
+func useTransport(t *testing.T, fn roundTripFunc) {
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = fn
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+}
+
+func jsonResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// playlistPage is a page of playlistItems starting at first, with a token for
+// the next page unless it is the last.
+func playlistPage(first int, count int, total int) string {
+	items := make([]string, 0, count)
+	for i := first; i < first+count && i < total; i++ {
+		items = append(items, fmt.Sprintf(
+			`{"contentDetails": {"videoId": "video%04d", "videoPublishedAt": "2020-01-01T00:00:00Z"}}`, i,
+		))
+	}
+
+	next := ""
+	if first+count < total {
+		next = fmt.Sprintf(`"nextPageToken": "page%d",`, first+count)
+	}
+	return fmt.Sprintf(
+		`{%s "pageInfo": {"totalResults": %d}, "items": [%s]}`,
+		next, total, strings.Join(items, ","),
+	)
+}
+
+func TestGetPlaylistVideosFailures(t *testing.T) {
+	const total = 120
+
+	tests := []struct {
+		name string
+		// second answers the request for the second page.
+		second  func(req *http.Request, cancel context.CancelFunc) (*http.Response, error)
+		wantErr func(err error) bool
+	}{
+		{
+			name: "error status",
+			second: func(req *http.Request, cancel context.CancelFunc) (*http.Response, error) {
+				return jsonResponse(req, http.StatusInternalServerError, `{"error": {"errors": [{"reason": "backendError"}]}}`), nil
+			},
+			wantErr: func(err error) bool {
+				var upstream *UpstreamError
+				return errors.As(err, &upstream) && upstream.Status == http.StatusInternalServerError
+			},
+		},
+		{
+			name: "transport error",
+			second: func(req *http.Request, cancel context.CancelFunc) (*http.Response, error) {
+				return nil, errors.New("connection reset")
+			},
+			wantErr: func(err error) bool {
+				var upstream *UpstreamError
+				return errors.As(err, &upstream) && upstream.Status == 0
+			},
+		},
+		{
+			name: "client disconnects",
+			second: func(req *http.Request, cancel context.CancelFunc) (*http.Response, error) {
+				cancel()
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			},
+			wantErr: func(err error) bool {
+				return errors.Is(err, context.Canceled)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			useTransport(t, func(req *http.Request) (*http.Response, error) {
+				query := req.URL.Query()
+				switch {
+				case query.Get("maxResults") == "1":
+					return jsonResponse(req, http.StatusOK, playlistPage(0, 1, total)), nil
+				case query.Get("pageToken") == "":
+					return jsonResponse(req, http.StatusOK, playlistPage(0, 50, total)), nil
+				default:
+					return test.second(req, cancel)
+				}
+			})
+
+			goroutines := runtime.NumGoroutine()
+			youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}}).WithContext(ctx)
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := youtube.GetPlaylistVideos("UUchannel")
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if err == nil || !test.wantErr(err) {
+					t.Fatalf("GetPlaylistVideos error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("GetPlaylistVideos did not return")
+			}
+
+			// The crawl winds down instead of staying blocked on its channels.
+			deadline := time.Now().Add(5 * time.Second)
+			for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if runtime.NumGoroutine() > goroutines {
+				t.Errorf("%d goroutines left running", runtime.NumGoroutine()-goroutines)
+			}
+		})
+	}
+}
+
+func TestGetPlaylistVideosStopsOnParseError(t *testing.T) {
+	const total = 300
+
+	var later atomic.Int32
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch {
+		case query.Get("maxResults") == "1":
+			return jsonResponse(req, http.StatusOK, playlistPage(0, 1, total)), nil
+		case query.Get("pageToken") == "":
+			// The token for the next page comes before the broken item.
+			return jsonResponse(req, http.StatusOK, fmt.Sprintf(
+				`{"nextPageToken": "page50", "pageInfo": {"totalResults": %d}, "items": [{"contentDetails": {}}]}`,
+				total,
+			)), nil
+		case query.Get("pageToken") == "page50":
+			// A page already being fetched is abandoned.
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(5 * time.Second):
+				t.Error("the request for the second page was not cancelled")
+				return jsonResponse(req, http.StatusOK, playlistPage(50, 50, total)), nil
+			}
+		default:
+			later.Add(1)
+			var first int
+			fmt.Sscanf(query.Get("pageToken"), "page%d", &first)
+			return jsonResponse(req, http.StatusOK, playlistPage(first, 50, total)), nil
+		}
+	})
+
+	goroutines := runtime.NumGoroutine()
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+
+	_, err := youtube.GetPlaylistVideos("UUchannel")
+	if err == nil || !strings.Contains(err.Error(), "videoId") {
+		t.Fatalf("GetPlaylistVideos error = %v, want the parse error", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if runtime.NumGoroutine() > goroutines {
+		t.Errorf("%d goroutines left running", runtime.NumGoroutine()-goroutines)
+	}
+	if later.Load() > 0 {
+		t.Errorf("%d more pages fetched after the parse error", later.Load())
+	}
+}
+
+func TestGetPlaylistVideos(t *testing.T) {
+	const total = 120
+
+	useTransport(t, func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		if query.Get("maxResults") == "1" {
+			return jsonResponse(req, http.StatusOK, playlistPage(0, 1, total)), nil
+		}
+		first := 0
+		fmt.Sscanf(query.Get("pageToken"), "page%d", &first)
+		return jsonResponse(req, http.StatusOK, playlistPage(first, 50, total)), nil
+	})
+
+	youtube := NewYouTubeService(Options{ApiKeys: []string{"key"}})
+	videos, err := youtube.GetPlaylistVideos("UUchannel")
+	if err != nil {
+		t.Fatalf("GetPlaylistVideos error = %s", err)
+	}
+	if len(videos) != total {
+		t.Fatalf("GetPlaylistVideos returned %d videos, want %d", len(videos), total)
+	}
+	for i, video := range videos {
+		if want := fmt.Sprintf("video%04d", i); video.VideoId != want {
+			t.Fatalf("videos[%d] = %s, want %s", i, video.VideoId, want)
+		}
+	}
+}